@@ -0,0 +1,87 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package littlebyte
+
+import "fmt"
+
+// AddBCD appends a string of decimal digits as packed binary-coded decimal,
+// two digits per byte with the first digit of each pair in the high nibble.
+// If there is an odd number of digits, the last low nibble is filled with 0xf.
+// If digits contains a character other than '0' to '9', an error is set on the
+// Builder.
+func (b *Builder) AddBCD(digits string) {
+	b.AddBCDFiller(digits, 0xf)
+}
+
+// AddBCDFiller is like AddBCD, but an odd number of digits is padded with the
+// given filler nibble, which must be between 0 and 0xf.
+func (b *Builder) AddBCDFiller(digits string, filler uint8) {
+	if filler > 0xf {
+		panic("littlebyte: invalid BCD filler nibble")
+	}
+	if b.err != nil {
+		return
+	}
+	for i := 0; i < len(digits); i++ {
+		if digits[i] < '0' || digits[i] > '9' {
+			b.err = fmt.Errorf("littlebyte: invalid BCD digit %q", digits[i])
+			return
+		}
+	}
+	v := b.extend((len(digits) + 1) / 2)
+	for i := range v {
+		lo := filler
+		if 2*i+1 < len(digits) {
+			lo = digits[2*i+1] - '0'
+		}
+		v[i] = (digits[2*i]-'0')<<4 | lo
+	}
+}
+
+// ReadBCD decodes nBytes bytes of packed binary-coded decimal, as written by
+// AddBCD, into out as a string of decimal digits and advances over them. A
+// final low nibble of 0xf is treated as filler and omitted. It reports whether
+// the read was successful, which requires that every other nibble is a
+// decimal digit.
+func (s *String) ReadBCD(out *string, nBytes int) bool {
+	return s.readBCD("ReadBCD", out, nBytes, 0xf)
+}
+
+// ReadBCDFiller is like ReadBCD, but reads values written by AddBCDFiller
+// with the given filler nibble, which must be between 0 and 0xf. A final low
+// nibble equal to filler is always omitted, so if filler is a decimal digit,
+// an even number of digits ending in it cannot be read back.
+func (s *String) ReadBCDFiller(out *string, nBytes int, filler uint8) bool {
+	if filler > 0xf {
+		panic("littlebyte: invalid BCD filler nibble")
+	}
+	return s.readBCD("ReadBCDFiller", out, nBytes, filler)
+}
+
+func (s *String) readBCD(name string, out *string, nBytes int, filler uint8) bool {
+	t := *s
+	v := t.read(nBytes)
+	if v == nil && nBytes != 0 {
+		return s.fail(name)
+	}
+	digits := make([]byte, 0, 2*nBytes)
+	for i, c := range v {
+		hi, lo := c>>4, c&0xf
+		if hi > 9 {
+			return s.fail(name)
+		}
+		digits = append(digits, '0'+hi)
+		if lo == filler && i == len(v)-1 {
+			break
+		}
+		if lo > 9 {
+			return s.fail(name)
+		}
+		digits = append(digits, '0'+lo)
+	}
+	*out = string(digits)
+	*s = t
+	return true
+}
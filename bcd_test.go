@@ -0,0 +1,86 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package littlebyte
+
+import "testing"
+
+func TestBCD(t *testing.T) {
+	tests := []struct {
+		digits string
+		want   []byte
+	}{
+		{"", nil},
+		{"1234", []byte{0x12, 0x34}},
+		{"12345", []byte{0x12, 0x34, 0x5f}},
+		{"0", []byte{0x0f}},
+	}
+	for _, tt := range tests {
+		var b Builder
+		b.AddBCD(tt.digits)
+		if err := builderBytesEq(&b, tt.want...); err != nil {
+			t.Errorf("AddBCD(%q): %v", tt.digits, err)
+			continue
+		}
+		s := NewString(b.BytesOrPanic())
+		var got string
+		if !s.ReadBCD(&got, len(tt.want)) || got != tt.digits {
+			t.Errorf("ReadBCD(%x) = %q, want %q", tt.want, got, tt.digits)
+		}
+	}
+
+	var b Builder
+	b.AddBCDFiller("123", 0)
+	if err := builderBytesEq(&b, 0x12, 0x30); err != nil {
+		t.Error(err)
+	}
+
+	for _, filler := range []uint8{0, 0xa, 0xf} {
+		for _, digits := range []string{"123", "1234"} {
+			if filler == 0 && digits == "1234" {
+				continue // ambiguous with "123"
+			}
+			var b Builder
+			b.AddBCDFiller(digits, filler)
+			s := NewString(b.BytesOrPanic())
+			var got string
+			if !s.ReadBCDFiller(&got, 2, filler) || got != digits {
+				t.Errorf("ReadBCDFiller(%#x) = %q, want %q", filler, got, digits)
+			}
+		}
+	}
+	s := NewString([]byte{0x1a})
+	var got string
+	if s.ReadBCD(&got, 1) {
+		t.Error("ReadBCD() = true for filler 0xa, want false")
+	}
+
+	b = Builder{}
+	b.AddBCD("12a")
+	if _, err := b.Bytes(); err == nil {
+		t.Error("Bytes() error = nil after AddBCD with a non-digit, want error")
+	}
+}
+
+func TestReadBCDInvalid(t *testing.T) {
+	tests := []struct {
+		in []byte
+		n  int
+	}{
+		{[]byte{0x1f, 0x23}, 2}, // Filler before the end.
+		{[]byte{0xa1}, 1},
+		{[]byte{0x1b}, 1},
+		{[]byte{0x12}, 2},
+	}
+	for _, tt := range tests {
+		s := NewString(tt.in)
+		var got string
+		if s.ReadBCD(&got, tt.n) {
+			t.Errorf("ReadBCD(%x, %d) = true, want false", tt.in, tt.n)
+		}
+		if s.Len() != len(tt.in) {
+			t.Errorf("ReadBCD(%x, %d) advanced on failure", tt.in, tt.n)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package littlebyte
+
+// A BitField describes a field of Width bits in a value read by
+// ReadBitFields. A field with an empty Name is skipped, which is useful for
+// reserved bits.
+type BitField struct {
+	Name  string
+	Width int
+}
+
+// ReadBitFields reads a little-endian value of totalBits bits, which must be a
+// multiple of 8 between 8 and 64, and advances over it. It splits the value
+// into fields, starting from the least significant bit, and returns a map
+// from each field's name to its value. Bits not covered by a field are
+// ignored. It reports whether the read was successful. It panics if totalBits
+// is invalid or the fields are wider than totalBits in total.
+func (s *String) ReadBitFields(totalBits int, fields []BitField) (map[string]uint64, bool) {
+	return s.readBitFields("ReadBitFields", totalBits, fields, false)
+}
+
+// ReadBitFieldsMSB is like ReadBitFields, but splits the value into fields
+// starting from the most significant bit, as register layouts are often
+// described.
+func (s *String) ReadBitFieldsMSB(totalBits int, fields []BitField) (map[string]uint64, bool) {
+	return s.readBitFields("ReadBitFieldsMSB", totalBits, fields, true)
+}
+
+func (s *String) readBitFields(name string, totalBits int, fields []BitField, msbFirst bool) (map[string]uint64, bool) {
+	if totalBits < 8 || totalBits > 64 || totalBits%8 != 0 {
+		panic("littlebyte: invalid bit field width")
+	}
+	used := 0
+	for _, f := range fields {
+		if f.Width < 0 || f.Width > totalBits-used {
+			panic("littlebyte: invalid bit field width")
+		}
+		used += f.Width
+	}
+	buf := s.read(totalBits / 8)
+	if buf == nil {
+		return nil, s.fail(name)
+	}
+	var v uint64
+	for i := len(buf) - 1; i >= 0; i-- {
+		v = v<<8 | uint64(buf[i])
+	}
+	out := make(map[string]uint64, len(fields))
+	shift := 0
+	for _, f := range fields {
+		pos := shift
+		if msbFirst {
+			pos = totalBits - shift - f.Width
+		}
+		if f.Name != "" {
+			out[f.Name] = v >> uint(pos) & (1<<uint(f.Width) - 1)
+		}
+		shift += f.Width
+	}
+	return out, true
+}
@@ -0,0 +1,67 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package littlebyte
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadBitFields(t *testing.T) {
+	fields := []BitField{
+		{"mode", 3},
+		{"enable", 1},
+		{"channel", 4},
+	}
+	s := NewString([]byte{0x9d, 0x9d})
+	got, ok := s.ReadBitFields(8, fields)
+	if !ok {
+		t.Fatal("ReadBitFields() = false, want true")
+	}
+	if want := map[string]uint64{"mode": 5, "enable": 1, "channel": 9}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadBitFields() = %v, want %v", got, want)
+	}
+	got, ok = s.ReadBitFieldsMSB(8, fields)
+	if !ok {
+		t.Fatal("ReadBitFieldsMSB() = false, want true")
+	}
+	if want := map[string]uint64{"mode": 4, "enable": 1, "channel": 13}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadBitFieldsMSB() = %v, want %v", got, want)
+	}
+
+	s = NewString([]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x80})
+	got, ok = s.ReadBitFieldsMSB(64, []BitField{{"top", 1}, {"", 62}, {"bottom", 1}})
+	if want := map[string]uint64{"top": 1, "bottom": 1}; !ok || !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadBitFieldsMSB() = %v, %v; want %v, true", got, ok, want)
+	}
+	got, ok = s.ReadBitFields(64, []BitField{{"all", 64}})
+	if ok || got != nil {
+		t.Errorf("ReadBitFields() = %v, %v on empty input; want nil, false", got, ok)
+	}
+	if got, want := s.Reason(), "littlebyte: ReadBitFields failed"; !strings.HasPrefix(got, want) {
+		t.Errorf("Reason() = %q, want it to start with %q", got, want)
+	}
+
+	for _, test := range []struct {
+		totalBits int
+		fields    []BitField
+	}{
+		{12, nil},
+		{72, nil},
+		{8, []BitField{{"a", 4}, {"b", 5}}},
+		{8, []BitField{{"a", -1}}},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("recover() = nil, want error; ReadBitFields(%d, %v) did not panic", test.totalBits, test.fields)
+				}
+			}()
+			s := NewString(make([]byte, 16))
+			s.ReadBitFields(test.totalBits, test.fields)
+		}()
+	}
+}
@@ -5,8 +5,12 @@
 package littlebyte
 
 import (
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"hash"
+	"io"
+	"math/bits"
 )
 
 // A Builder builds byte strings from fixed-length and length-prefixed values.
@@ -27,7 +31,39 @@ type Builder struct {
 	child          *Builder
 	offset         int
 	pendingLenLen  int
+	pendingValue   func([]byte) (uint64, error)
+	pendingUvarint bool
+	pendingAutoLen bool
 	inContinuation *bool
+	stats          BuilderStats
+	stream         *stream
+	children       []int
+	strict         bool
+	trackSections  bool
+	sections       []int
+	tee            hash.Hash
+	teed           int
+	depth          int
+	owned          bool
+}
+
+// BuilderStats contains counters describing the work done by a Builder. They
+// are intended for measuring and testing the performance of building code.
+type BuilderStats struct {
+	// BytesWritten is the number of bytes appended to the Builder, including
+	// length prefixes. Bytes removed by Unwrite are still counted.
+	BytesWritten int
+	// Reallocations is the number of times the Builder's buffer was
+	// reallocated because its capacity was exceeded.
+	Reallocations int
+	// ChildrenOpened is the number of length-prefixed values started.
+	ChildrenOpened int
+}
+
+func (s *BuilderStats) add(t BuilderStats) {
+	s.BytesWritten += t.BytesWritten
+	s.Reallocations += t.Reallocations
+	s.ChildrenOpened += t.ChildrenOpened
 }
 
 // NewBuilder creates a Builder that appends its output to the given buffer.
@@ -49,28 +85,193 @@ func NewFixedBuilder(buffer []byte) *Builder {
 	}
 }
 
+// FillInto calls f with a fixed-size Builder that writes into buf, from its
+// start up to its capacity, and returns the number of bytes written. It
+// returns an error if one occurred during building, including if the output
+// would not fit, in which case the contents of buf are unspecified. This suits
+// callers that pass buf and a length to another API, rather than a slice of
+// the output.
+func FillInto(buf []byte, f BuilderContinuation) (n int, err error) {
+	b := NewFixedBuilder(buf[:0])
+	b.inContinuation = new(bool)
+	b.callContinuation(f, b)
+	v, err := b.Bytes()
+	if err != nil {
+		return 0, err
+	}
+	return len(v), nil
+}
+
 // SetError sets the value to be returned as the error from Bytes. Writes
 // performed after calling SetError are ignored.
 func (b *Builder) SetError(err error) {
 	b.err = err
 }
 
+// SetStrictLengthChecks sets whether the Builder checks that each
+// length-prefixed value fits in its prefix as soon as its continuation
+// returns. By default, an overflow is recorded and returned as the error from
+// Bytes. In strict mode, it instead panics with a BuildError, which is not
+// recovered by enclosing continuations, so that the stack trace points at the
+// offending call. The setting is inherited by child builders.
+func (b *Builder) SetStrictLengthChecks(strict bool) {
+	b.strict = strict
+}
+
+// lengthCheckError is the error panicked, wrapped in a BuildError, by a
+// Builder with strict length checks.
+type lengthCheckError struct {
+	err error
+}
+
+func (e lengthCheckError) Error() string { return e.err.Error() }
+
 // Bytes returns the bytes written by the builder or an error if one has
-// occurred during building.
+// occurred during building. It is an error to call Bytes while a
+// length-prefixed child is pending, since its length prefix has not been
+// written yet. The error describes the pending children, and an error for a
+// length that overflowed its prefix gives the width of the prefix and its
+// nesting depth, where 1 is a child of the top-level Builder. If a hash was
+// set with TeeHash, the returned bytes are written to it.
 func (b *Builder) Bytes() ([]byte, error) {
 	if b.err != nil {
 		return nil, b.err
 	}
+	if b.child != nil {
+		return nil, b.pendingChildError("Bytes")
+	}
+	b.feedTee(b.result, len(b.result))
 	return b.result[b.offset:], nil
 }
 
+// pendingChildError returns the error reported when method is called while a
+// child is pending.
+func (b *Builder) pendingChildError(method string) error {
+	return fmt.Errorf("littlebyte: %s called with %d pending children, the outermost at offset %d", method, b.PendingDepth(), b.child.offset-b.offset)
+}
+
 // BytesOrPanic returns the bytes written by the builder or panics if an error
-// has occurred during building.
+// has occurred during building, or if a child is pending.
 func (b *Builder) BytesOrPanic() []byte {
-	if b.err != nil {
-		panic(b.err)
+	v, err := b.Bytes()
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// Clone returns a new Builder containing a copy of the bytes written to b and
+// its error state. The clone does not share a buffer with b, so subsequent
+// writes to either do not affect the other. If b is fixed-size, the clone's
+// buffer has the same capacity as the remainder of b's. Clone panics if b has a
+// pending child.
+func (b *Builder) Clone() *Builder {
+	if b.child != nil {
+		panic("littlebyte: Clone called while child is pending")
+	}
+	v := b.result[b.offset+b.pendingLenLen:]
+	result := make([]byte, len(v), cap(v))
+	copy(result, v)
+	var children []int
+	for i := 0; i < len(b.children); i += 2 {
+		start := b.children[i] - (b.offset + b.pendingLenLen)
+		end := b.children[i+1] - (b.offset + b.pendingLenLen)
+		if end <= 0 {
+			continue
+		}
+		if start < 0 {
+			start = 0
+		}
+		children = append(children, start, end)
+	}
+	return &Builder{
+		err:       b.err,
+		result:    result,
+		fixedSize: b.fixedSize,
+		strict:    b.strict,
+		children:  children,
+		owned:     !b.fixedSize,
+	}
+}
+
+// Stats returns counters describing the work done by the Builder and its
+// children so far.
+func (b *Builder) Stats() BuilderStats {
+	stats := b.stats
+	if b.child != nil {
+		stats.add(b.child.Stats())
+	}
+	return stats
+}
+
+// Grow grows the Builder's buffer, if necessary, to guarantee space for n
+// more bytes without another reallocation. It does nothing for a fixed-size
+// Builder, whose buffer is never reallocated.
+func (b *Builder) Grow(n int) {
+	if n < 0 {
+		panic("littlebyte: negative count")
+	}
+	if b.child != nil {
+		panic("littlebyte: Grow called while child is pending")
+	}
+	if b.err != nil || b.fixedSize || len(b.result)+n <= cap(b.result) {
+		return
+	}
+	result := make([]byte, len(b.result), len(b.result)+n)
+	copy(result, b.result)
+	b.result = result
+	b.owned = true
+	b.stats.Reallocations++
+}
+
+// Len returns the number of bytes written to the Builder so far. For a child
+// passed to a BuilderContinuation, this does not include the length prefix.
+// For a streaming Builder, it includes bytes that have already been flushed.
+func (b *Builder) Len() int {
+	if b.child != nil {
+		panic("littlebyte: Len called while child is pending")
+	}
+	n := len(b.result) - b.pendingLenLen - b.offset
+	if b.stream != nil && b.stream.root == b {
+		n += b.stream.flushed
+	}
+	return n
+}
+
+// Cap returns the number of bytes that can be written to a fixed-size Builder
+// in total, counted in the same way as Len. For a Builder that allocates space
+// as needed, it returns the maximum value of an int.
+func (b *Builder) Cap() int {
+	if !b.fixedSize {
+		return maxInt
 	}
-	return b.result[b.offset:]
+	return cap(b.result) - b.pendingLenLen - b.offset
+}
+
+// IsFixed reports whether b writes into a fixed-size buffer, as created by
+// NewFixedBuilder, including a child of such a Builder. Writes that exceed a
+// fixed-size buffer set an error, so callers may wish to check Available
+// before attempting a large write.
+func (b *Builder) IsFixed() bool {
+	return b.fixedSize
+}
+
+// Available returns the number of bytes that can still be written to the
+// Builder, which is Cap minus Len.
+func (b *Builder) Available() int {
+	return b.Cap() - b.Len()
+}
+
+// PendingDepth returns the number of length-prefixed values that have been
+// started on b, directly or through its children, and not yet completed. It is
+// 0 unless called on a Builder whose continuation is still running, such as
+// the top-level Builder from within a continuation.
+func (b *Builder) PendingDepth() int {
+	n := 0
+	for c := b.child; c != nil; c = c.child {
+		n++
+	}
+	return n
 }
 
 // AddUint8 appends an 8-bit value to the byte string.
@@ -78,6 +279,15 @@ func (b *Builder) AddUint8(v uint8) {
 	b.add(byte(v))
 }
 
+// AddBool appends a boolean as an 8-bit value: 1 for true and 0 for false.
+func (b *Builder) AddBool(v bool) {
+	if v {
+		b.add(1)
+	} else {
+		b.add(0)
+	}
+}
+
 // AddUint16 appends a little-endian, 16-bit value to the byte string.
 func (b *Builder) AddUint16(v uint16) {
 	b.add(byte(v), byte(v>>8))
@@ -94,9 +304,270 @@ func (b *Builder) AddUint32(v uint32) {
 	b.add(byte(v), byte(v>>8), byte(v>>16), byte(v>>24))
 }
 
+// TryAddUint8 is like AddUint8, but it returns the Builder's error, if any,
+// for use in code that handles errors as values.
+func (b *Builder) TryAddUint8(v uint8) error {
+	b.AddUint8(v)
+	return b.err
+}
+
+// TryAddUint16 is like AddUint16, but it returns the Builder's error, if any.
+func (b *Builder) TryAddUint16(v uint16) error {
+	b.AddUint16(v)
+	return b.err
+}
+
+// TryAddUint24 is like AddUint24, but it returns the Builder's error, if any.
+func (b *Builder) TryAddUint24(v uint32) error {
+	b.AddUint24(v)
+	return b.err
+}
+
+// TryAddUint32 is like AddUint32, but it returns the Builder's error, if any.
+func (b *Builder) TryAddUint32(v uint32) error {
+	b.AddUint32(v)
+	return b.err
+}
+
+// AddFixedString appends v as a field of exactly width bytes, followed by
+// copies of pad to fill the rest of the field. If v is longer than width, an
+// error is set on the Builder rather than truncating it.
+func (b *Builder) AddFixedString(v string, width int, pad byte) {
+	if width < 0 {
+		panic("littlebyte: negative width")
+	}
+	if b.err != nil {
+		return
+	}
+	if len(v) > width {
+		b.err = fmt.Errorf("littlebyte: string of length %d exceeds %d-byte field", len(v), width)
+		return
+	}
+	b.AddString(v)
+	b.AddRepeated(pad, width-len(v))
+}
+
+// AddHex decodes the hexadecimal string s and appends the resulting bytes to
+// the byte string. If s has odd length or contains a non-hexadecimal
+// character, an error is set on the Builder and nothing is appended.
+func (b *Builder) AddHex(s string) {
+	if b.err != nil {
+		return
+	}
+	v, err := hex.DecodeString(s)
+	if err != nil {
+		b.err = fmt.Errorf("littlebyte: invalid hex string: %v", err)
+		return
+	}
+	b.add(v...)
+}
+
+// AddUint appends the low width bytes of v, in little-endian order, to the
+// byte string. It panics if width is not between 1 and 8.
+func (b *Builder) AddUint(v uint64, width int) {
+	if width < 1 || width > 8 {
+		panic("littlebyte: invalid integer width")
+	}
+	var buf [8]byte
+	for i := 0; i < width; i++ {
+		buf[i] = byte(v)
+		v >>= 8
+	}
+	b.add(buf[:width]...)
+}
+
+// AddString appends the bytes of v to the byte string, without first
+// converting it to a []byte.
+func (b *Builder) AddString(v string) {
+	if !b.canAdd(len(v)) {
+		return
+	}
+	b.result = append(b.result, v...)
+}
+
+// AddUint8LengthPrefixedUint64 appends v as an 8-bit length-prefixed,
+// little-endian integer using the minimal number of bytes. Zero is encoded
+// with no bytes.
+func (b *Builder) AddUint8LengthPrefixedUint64(v uint64) {
+	n := (bits.Len64(v) + 7) / 8
+	b.AddUint8(uint8(n))
+	for i := 0; i < n; i++ {
+		b.AddUint8(uint8(v))
+		v >>= 8
+	}
+}
+
 // AddBytes appends a sequence of bytes to the byte string.
 func (b *Builder) AddBytes(v []byte) {
+	start := len(b.result)
 	b.add(v...)
+	if b.trackSections && b.err == nil {
+		b.sections = append(b.sections, start, len(b.result))
+	}
+}
+
+// AddBytesNoCopy is like AddBytes, but if nothing has been written to the
+// Builder and it has no buffer yet, it takes ownership of v instead of copying
+// it. Otherwise, v is copied as by AddBytes. A streaming Builder always copies
+// v, since Flush reuses its buffer.
+//
+// Because the Builder's buffer may then be v itself, the caller must not
+// modify v afterwards, and methods that overwrite bytes already written, such
+// as Unwrite or Rollback followed by an append, InsertAt, or a patch function
+// returned by Reserve, may modify v. Bytes may also return v. Later appends
+// never write beyond len(v), so the rest of v's underlying array is not
+// affected. Release does not keep a buffer taken from v for reuse.
+func (b *Builder) AddBytesNoCopy(v []byte) {
+	if b.err != nil || b.child != nil || b.fixedSize || b.stream != nil || cap(b.result) != 0 {
+		b.AddBytes(v)
+		return
+	}
+	b.stats.BytesWritten += len(v)
+	b.result = v[:len(v):len(v)]
+	b.owned = false
+	if b.trackSections {
+		b.sections = append(b.sections, 0, len(v))
+	}
+}
+
+// AddBuilder appends the bytes written to other, as returned by its Bytes
+// method. If other has an error, including because it has a pending child, the
+// error is set on b instead.
+func (b *Builder) AddBuilder(other *Builder) {
+	if b.err != nil {
+		return
+	}
+	v, err := other.Bytes()
+	if err != nil {
+		b.err = err
+		return
+	}
+	b.AddBytes(v)
+}
+
+// AddBytesFrom reads exactly n bytes from r directly into the byte string,
+// without an intermediate buffer. If fewer than n bytes can be read, the bytes
+// that were read are discarded and the error, as returned by io.ReadFull, is
+// set on the Builder. It returns the Builder's error, if any.
+func (b *Builder) AddBytesFrom(r io.Reader, n int) error {
+	if n < 0 {
+		panic("littlebyte: negative count")
+	}
+	v := b.extend(n)
+	if b.err != nil {
+		return b.err
+	}
+	if _, err := io.ReadFull(r, v); err != nil {
+		b.result = b.result[:len(b.result)-n]
+		b.err = err
+	}
+	return b.err
+}
+
+// AddUint16Slice appends each element of vs as a little-endian, 16-bit value
+// to the byte string.
+func (b *Builder) AddUint16Slice(vs []uint16) {
+	v := b.extend(len(vs) * 2)
+	if v == nil {
+		return
+	}
+	for i, x := range vs {
+		v[2*i] = byte(x)
+		v[2*i+1] = byte(x >> 8)
+	}
+}
+
+// AddUint32Slice appends each element of vs as a little-endian, 32-bit value
+// to the byte string.
+func (b *Builder) AddUint32Slice(vs []uint32) {
+	v := b.extend(len(vs) * 4)
+	if v == nil {
+		return
+	}
+	for i, x := range vs {
+		v[4*i] = byte(x)
+		v[4*i+1] = byte(x >> 8)
+		v[4*i+2] = byte(x >> 16)
+		v[4*i+3] = byte(x >> 24)
+	}
+}
+
+// AddZeros appends n zero bytes to the byte string.
+func (b *Builder) AddZeros(n int) {
+	if n < 0 {
+		panic("littlebyte: negative count")
+	}
+	b.extend(n)
+}
+
+// AddRepeated appends n copies of value to the byte string.
+func (b *Builder) AddRepeated(value byte, n int) {
+	if n < 0 {
+		panic("littlebyte: negative count")
+	}
+	v := b.extend(n)
+	for i := range v {
+		v[i] = value
+	}
+}
+
+// PadToCapacity fills the remaining capacity of a fixed-size Builder's buffer
+// with copies of pad, for example 0xff when building a flash memory image. It
+// panics if the Builder is not fixed-size.
+func (b *Builder) PadToCapacity(pad byte) {
+	if !b.fixedSize {
+		panic("littlebyte: PadToCapacity called on a Builder that is not fixed-size")
+	}
+	b.AddRepeated(pad, cap(b.result)-len(b.result))
+}
+
+// Reserve appends n zero bytes to be filled in later, for example with a
+// forward reference, and returns a function that overwrites them. The function
+// must be called with exactly n bytes. It writes into the Builder's current
+// buffer, so it remains valid if the buffer is reallocated by later writes,
+// but a child passed to a BuilderContinuation must call it before the
+// continuation returns. The function panics if the Builder has a pending
+// child when it is called, or if the reserved bytes have since been unwritten.
+func (b *Builder) Reserve(n int) (patch func([]byte)) {
+	if n < 0 {
+		panic("littlebyte: negative count")
+	}
+	offset := b.flushedLen() + len(b.result)
+	b.AddZeros(n)
+	return func(v []byte) {
+		if len(v) != n {
+			panic("littlebyte: patch length does not match reserved length")
+		}
+		if b.err != nil {
+			return
+		}
+		if b.child != nil {
+			panic("littlebyte: attempted patch while child is pending")
+		}
+		i := offset - b.flushedLen()
+		if i < 0 {
+			panic("littlebyte: attempted patch of flushed bytes")
+		}
+		if i < b.teed {
+			panic("littlebyte: attempted patch of hashed bytes")
+		}
+		if i+n > len(b.result) {
+			panic("littlebyte: attempted patch of unwritten bytes")
+		}
+		copy(b.result[i:], v)
+	}
+}
+
+// AlignTo appends pad bytes until the number of bytes written to the Builder,
+// as reported by Len, is a multiple of n. n must be a power of two.
+func (b *Builder) AlignTo(n int, pad byte) {
+	if n <= 0 || n&(n-1) != 0 {
+		panic("littlebyte: alignment is not a power of two")
+	}
+	if b.err != nil {
+		return
+	}
+	b.AddRepeated(pad, -b.Len()&(n-1))
 }
 
 // BuilderContinuation is a continuation-passing interface for building
@@ -140,6 +611,18 @@ func (b *Builder) AddUint16LengthPrefixed(f BuilderContinuation) {
 	b.addLengthPrefixed(2, false, f)
 }
 
+// AddUint16LengthPrefixedLen is like AddUint16LengthPrefixed, but it also
+// returns the length of the content written by f, excluding the prefix. It
+// returns 0 if the Builder has encountered an error.
+func (b *Builder) AddUint16LengthPrefixedLen(f BuilderContinuation) int {
+	start := b.Len()
+	b.AddUint16LengthPrefixed(f)
+	if b.err != nil {
+		return 0
+	}
+	return b.Len() - start - 2
+}
+
 // AddUint24LengthPrefixed adds a little-endian, 24-bit length-prefixed byte sequence.
 func (b *Builder) AddUint24LengthPrefixed(f BuilderContinuation) {
 	b.addLengthPrefixed(3, false, f)
@@ -150,6 +633,132 @@ func (b *Builder) AddUint32LengthPrefixed(f BuilderContinuation) {
 	b.addLengthPrefixed(4, false, f)
 }
 
+// AddUint32LengthPrefixedSized adds a little-endian, 32-bit length-prefixed
+// byte sequence whose length is known in advance to be size. The prefix is
+// written immediately rather than once f returns. If the length of the
+// content written by f differs from size, an error is set on the Builder.
+func (b *Builder) AddUint32LengthPrefixedSized(size int, f BuilderContinuation) {
+	if size < 0 {
+		panic("littlebyte: negative size")
+	}
+	if uint64(size) > 0xffffffff {
+		b.SetError(fmt.Errorf("littlebyte: size %d exceeds 4-byte length prefix", size))
+		return
+	}
+	b.AddUint32(uint32(size))
+	b.addPrefixed(0, func(v []byte) (uint64, error) {
+		if len(v) != size {
+			return 0, fmt.Errorf("littlebyte: pending child length %d does not match declared size %d", len(v), size)
+		}
+		return 0, nil
+	}, f)
+}
+
+// addEach adds n lenLen-byte length-prefixed elements, calling fn with the
+// index of each to write its content.
+func (b *Builder) addEach(lenLen, n int, fn func(child *Builder, i int)) {
+	for i := 0; i < n; i++ {
+		i := i
+		b.addLengthPrefixed(lenLen, false, func(child *Builder) {
+			fn(child, i)
+		})
+	}
+}
+
+// AddEachUint8LengthPrefixed adds n 8-bit length-prefixed byte sequences. The
+// content of each is written by calling fn with a child Builder and the index
+// of the element, from 0 to n-1, in the same way as for
+// AddUint8LengthPrefixed.
+func (b *Builder) AddEachUint8LengthPrefixed(n int, fn func(child *Builder, i int)) {
+	b.addEach(1, n, fn)
+}
+
+// AddEachUint16LengthPrefixed is like AddEachUint8LengthPrefixed, but for
+// little-endian, 16-bit length-prefixed byte sequences.
+func (b *Builder) AddEachUint16LengthPrefixed(n int, fn func(child *Builder, i int)) {
+	b.addEach(2, n, fn)
+}
+
+// AddEachUint24LengthPrefixed is like AddEachUint8LengthPrefixed, but for
+// little-endian, 24-bit length-prefixed byte sequences.
+func (b *Builder) AddEachUint24LengthPrefixed(n int, fn func(child *Builder, i int)) {
+	b.addEach(3, n, fn)
+}
+
+// AddEachUint32LengthPrefixed is like AddEachUint8LengthPrefixed, but for
+// little-endian, 32-bit length-prefixed byte sequences.
+func (b *Builder) AddEachUint32LengthPrefixed(n int, fn func(child *Builder, i int)) {
+	b.addEach(4, n, fn)
+}
+
+// AddFixedRecord adds a record of exactly size bytes. The content of the record
+// is written by f, which is called with the Builder itself, and is padded with
+// zeros to fill the record. If f writes more than size bytes, an error is set
+// on the Builder.
+func (b *Builder) AddFixedRecord(size int, f BuilderContinuation) {
+	if size < 0 {
+		panic("littlebyte: negative record size")
+	}
+	if b.err != nil {
+		return
+	}
+	if b.inContinuation == nil {
+		b.inContinuation = new(bool)
+	}
+
+	start := b.Len()
+	b.callContinuation(f, b)
+	if b.err != nil {
+		return
+	}
+	n := b.Len() - start
+	if n < 0 {
+		panic("littlebyte: record content was unwritten")
+	}
+	if n > size {
+		b.err = fmt.Errorf("littlebyte: record length %d exceeds %d-byte record size", n, size)
+		return
+	}
+	b.AddZeros(size - n)
+}
+
+// AddUint16ElementPrefixed adds a byte sequence of elemSize-byte elements,
+// prefixed with the little-endian, 16-bit count of elements. The elements are
+// written by f, in the same way as for AddUint16LengthPrefixed. If the length
+// of the content is not a multiple of elemSize, an error is set on the
+// Builder.
+func (b *Builder) AddUint16ElementPrefixed(elemSize int, f BuilderContinuation) {
+	if elemSize <= 0 {
+		panic("littlebyte: invalid element size")
+	}
+	b.addPrefixed(2, func(v []byte) (uint64, error) {
+		if len(v)%elemSize != 0 {
+			return 0, fmt.Errorf("littlebyte: pending child length %d is not a multiple of %d-byte element size", len(v), elemSize)
+		}
+		n := len(v) / elemSize
+		if n > 0xffff {
+			return 0, fmt.Errorf("littlebyte: pending child element count %d exceeds 2-byte prefix", n)
+		}
+		return uint64(n), nil
+	}, f)
+}
+
+// AddUint16LengthPrefixedMax is like AddUint16LengthPrefixed, but if the
+// length of the content exceeds max, an error is set on the Builder, even if
+// the length would fit in the prefix. This enforces limits imposed by a
+// specification that are stricter than the width of its length fields.
+func (b *Builder) AddUint16LengthPrefixedMax(max int, f BuilderContinuation) {
+	if max < 0 {
+		panic("littlebyte: negative maximum length")
+	}
+	b.addPrefixed(2, func(v []byte) (uint64, error) {
+		if len(v) > max {
+			return 0, fmt.Errorf("littlebyte: pending child length %d exceeds maximum of %d", len(v), max)
+		}
+		return uint64(len(v)), nil
+	}, f)
+}
+
 func (b *Builder) callContinuation(f BuilderContinuation, arg *Builder) {
 	if !*b.inContinuation {
 		*b.inContinuation = true
@@ -163,6 +772,9 @@ func (b *Builder) callContinuation(f BuilderContinuation, arg *Builder) {
 			}
 
 			if buildError, ok := r.(BuildError); ok {
+				if _, ok := buildError.Err.(lengthCheckError); ok {
+					panic(r)
+				}
 				b.err = buildError.Err
 			} else {
 				panic(r)
@@ -175,26 +787,43 @@ func (b *Builder) callContinuation(f BuilderContinuation, arg *Builder) {
 
 func (b *Builder) addLengthPrefixed(lenLen int, isASN1 bool, f BuilderContinuation) {
 	_ = isASN1
+	b.addPrefixed(lenLen, nil, f)
+}
+
+// addPrefixed reserves lenLen bytes and calls f to write the content that
+// follows them. Once f returns, the reserved bytes are filled in with the
+// little-endian result of calling value with the content, or with the length
+// of the content if value is nil. If value returns an error, it is set on the
+// Builder.
+func (b *Builder) addPrefixed(lenLen int, value func([]byte) (uint64, error), f BuilderContinuation) {
+	b.addChild(&Builder{pendingLenLen: lenLen, pendingValue: value}, f)
+}
 
+// addChild reserves space for the prefix described by the pending fields of
+// child and calls f with child to write the content that follows it. Once f
+// returns, the prefix is filled in by flushChild.
+func (b *Builder) addChild(child *Builder, f BuilderContinuation) {
 	// Subsequent writes can be ignored if the builder has encountered an error.
 	if b.err != nil {
 		return
 	}
 
 	offset := len(b.result)
-	b.add(make([]byte, lenLen)...)
+	b.add(make([]byte, child.pendingLenLen)...)
+	b.stats.ChildrenOpened++
 
 	if b.inContinuation == nil {
 		b.inContinuation = new(bool)
 	}
 
-	b.child = &Builder{
-		result:         b.result,
-		fixedSize:      b.fixedSize,
-		offset:         offset,
-		pendingLenLen:  lenLen,
-		inContinuation: b.inContinuation,
-	}
+	child.result = b.result
+	child.fixedSize = b.fixedSize
+	child.offset = offset
+	child.inContinuation = b.inContinuation
+	child.depth = b.depth + 1
+	child.stream = b.stream
+	child.strict = b.strict
+	b.child = child
 
 	b.callContinuation(f, b.child)
 	b.flushChild()
@@ -210,6 +839,7 @@ func (b *Builder) flushChild() {
 	b.child.flushChild()
 	child := b.child
 	b.child = nil
+	b.stats.add(child.stats)
 
 	if child.err != nil {
 		b.err = child.err
@@ -222,14 +852,44 @@ func (b *Builder) flushChild() {
 		panic("littlebyte: internal error") // result unexpectedly shrunk
 	}
 
-	l := length
-	for i := 0; i < child.pendingLenLen; i++ {
-		child.result[child.offset+i] = uint8(l)
-		l >>= 8
-	}
-	if l != 0 {
-		b.err = fmt.Errorf("littlebyte: pending child length %d exceeds %d-byte length prefix", length, child.pendingLenLen)
-		return
+	if child.pendingUvarint || child.pendingAutoLen {
+		if err := child.putVarPrefix(length); err != nil {
+			if lc, ok := err.(lengthCheckError); ok {
+				if b.strict {
+					panic(BuildError{Err: lc})
+				}
+				err = lc.err
+			}
+			b.err = err
+			return
+		}
+	} else {
+		l := uint64(length)
+		if child.pendingValue != nil {
+			var err error
+			if l, err = child.pendingValue(child.result[child.offset+child.pendingLenLen:]); err != nil {
+				b.err = err
+				return
+			}
+		}
+		v := l
+		for i := 0; i < child.pendingLenLen; i++ {
+			child.result[child.offset+i] = uint8(l)
+			l >>= 8
+		}
+		if l != 0 {
+			var err error
+			if child.pendingValue != nil {
+				err = fmt.Errorf("littlebyte: prefix value %#x exceeds %d-byte prefix at depth %d", v, child.pendingLenLen, child.depth)
+			} else {
+				err = fmt.Errorf("littlebyte: pending child length %d exceeds %d-byte length prefix at depth %d", length, child.pendingLenLen, child.depth)
+			}
+			if b.strict {
+				panic(BuildError{Err: lengthCheckError{err}})
+			}
+			b.err = err
+			return
+		}
 	}
 
 	if b.fixedSize && &b.result[0] != &child.result[0] {
@@ -237,28 +897,81 @@ func (b *Builder) flushChild() {
 	}
 
 	b.result = child.result
+	b.owned = b.owned || child.owned
+	b.children = append(b.children, child.offset, len(b.result))
+}
+
+// The children field of a Builder holds the start and end positions in its
+// buffer of each completed child, including its prefix, in pairs.
+
+// insideChild reports whether pos falls strictly inside a completed child, so
+// that truncating the buffer there would leave its length prefix wrong.
+func (b *Builder) insideChild(pos int) bool {
+	for i := len(b.children) - 2; i >= 0; i -= 2 {
+		if b.children[i] < pos {
+			return b.children[i+1] > pos
+		}
+	}
+	return false
+}
+
+// trimChildren discards the recorded children that have been unwritten.
+func (b *Builder) trimChildren() {
+	n := len(b.children)
+	for n > 0 && b.children[n-1] > len(b.result) {
+		n -= 2
+	}
+	b.children = b.children[:n]
 }
 
 func (b *Builder) add(bytes ...byte) {
-	if b.err != nil {
+	if !b.canAdd(len(bytes)) {
 		return
 	}
+	b.result = append(b.result, bytes...)
+}
+
+// extend appends n zero bytes and returns them so that they can be filled in
+// place. It returns nil if the bytes could not be added.
+func (b *Builder) extend(n int) []byte {
+	if !b.canAdd(n) {
+		return nil
+	}
+	m := len(b.result)
+	b.result = append(b.result, make([]byte, n)...)
+	return b.result[m:]
+}
+
+// canAdd reports whether n bytes can be appended to the Builder, setting an
+// error if they would overflow its length or its fixed-size buffer.
+func (b *Builder) canAdd(n int) bool {
+	if b.err != nil {
+		return false
+	}
 	if b.child != nil {
 		panic("littlebyte: attempted write while child is pending")
 	}
-	if len(b.result)+len(bytes) < len(bytes) {
+	if len(b.result)+n < n {
 		b.err = errors.New("littlebyte: length overflow")
+		return false
 	}
-	if b.fixedSize && len(b.result)+len(bytes) > cap(b.result) {
+	if b.fixedSize && len(b.result)+n > cap(b.result) {
 		b.err = errors.New("littlebyte: Builder is exceeding its fixed-size buffer")
-		return
+		return false
 	}
-	b.result = append(b.result, bytes...)
+	b.stats.BytesWritten += n
+	if len(b.result)+n > cap(b.result) {
+		b.stats.Reallocations++
+		b.owned = true
+	}
+	return true
 }
 
 // Unwrite rolls back n bytes written directly to the Builder. An attempt by a
 // child builder passed to a continuation to unwrite bytes from its parent will
-// panic.
+// panic. Length-prefixed values are final once their continuation returns, so
+// an attempt to unwrite part of one panics with a BuildError. A whole value,
+// including its prefix, may be unwritten.
 func (b *Builder) Unwrite(n int) {
 	if b.err != nil {
 		return
@@ -271,9 +984,99 @@ func (b *Builder) Unwrite(n int) {
 		panic("littlebyte: internal error")
 	}
 	if n > length {
+		if b.stream != nil && b.stream.root == b && n <= length+b.stream.flushed {
+			panic("littlebyte: attempted to unwrite flushed bytes")
+		}
 		panic("littlebyte: attempted to unwrite more than was written")
 	}
+	if len(b.result)-n < b.teed {
+		panic("littlebyte: attempted to unwrite hashed bytes")
+	}
+	if b.insideChild(len(b.result) - n) {
+		panic(BuildError{Err: errors.New("littlebyte: attempted to unwrite part of a completed length-prefixed value")})
+	}
+	b.result = b.result[:len(b.result)-n]
+	b.trimChildren()
+	b.trimSections()
+}
+
+// Mark returns the current position in the Builder, as reported by Len, to be
+// passed to Rollback.
+func (b *Builder) Mark() int {
+	return b.Len()
+}
+
+// Rollback discards the bytes written to the Builder since mark was returned
+// by Mark, including any length-prefixed values completed since then. This
+// allows a value to be built speculatively and abandoned. An error set on the
+// Builder is not discarded. Rollback panics if a child is pending or if mark
+// is not a position in the Builder.
+func (b *Builder) Rollback(mark int) {
+	if b.err != nil {
+		return
+	}
+	if b.child != nil {
+		panic("littlebyte: Rollback called while child is pending")
+	}
+	n := b.Len() - mark
+	if n < 0 || n > len(b.result)-b.pendingLenLen-b.offset {
+		panic("littlebyte: invalid rollback mark")
+	}
+	if len(b.result)-n < b.teed {
+		panic("littlebyte: attempted rollback of hashed bytes")
+	}
 	b.result = b.result[:len(b.result)-n]
+	b.trimChildren()
+	b.trimSections()
+}
+
+// InsertAt inserts data at offset, a position in the Builder as reported by
+// Len, shifting the bytes after it. Since every byte after offset is moved,
+// this is expensive and appending should be preferred where possible. The
+// length prefixes of completed values are not updated, so offset should not
+// fall inside one, and a patch function returned by Reserve for bytes after
+// offset must not be used afterwards. InsertAt panics if a child is pending
+// or if offset is not a position in the Builder.
+func (b *Builder) InsertAt(offset int, data []byte) {
+	if b.err != nil {
+		return
+	}
+	if b.child != nil {
+		panic("littlebyte: InsertAt called while child is pending")
+	}
+	length := b.Len()
+	if offset < 0 || offset > length {
+		panic("littlebyte: invalid insertion offset")
+	}
+	i := len(b.result) - (length - offset)
+	if i < b.offset+b.pendingLenLen {
+		panic("littlebyte: attempted insert before flushed bytes")
+	}
+	if i < b.teed {
+		panic("littlebyte: attempted insert before hashed bytes")
+	}
+	n := len(data)
+	if b.extend(n) == nil {
+		return
+	}
+	copy(b.result[i+n:], b.result[i:])
+	copy(b.result[i:], data)
+	for j := 0; j < len(b.children); j += 2 {
+		if b.children[j] >= i {
+			b.children[j] += n
+			b.children[j+1] += n
+		} else if b.children[j+1] > i {
+			b.children[j+1] += n
+		}
+	}
+	for j := 0; j < len(b.sections); j += 2 {
+		if b.sections[j] >= i {
+			b.sections[j] += n
+			b.sections[j+1] += n
+		} else if b.sections[j+1] > i {
+			b.sections[j+1] += n
+		}
+	}
 }
 
 // A MarshalingValue marshals itself into a Builder.
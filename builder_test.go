@@ -8,6 +8,8 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"io"
+	"strings"
 	"testing"
 )
 
@@ -76,7 +78,7 @@ func TestBytes(t *testing.T) {
 	if err := builderBytesEq(&b, v...); err != nil {
 		t.Error(err)
 	}
-	s := String(b.BytesOrPanic())
+	s := NewString(b.BytesOrPanic())
 	for _, w := range []string{"foo", "bar", "baz"} {
 		var got []byte
 		if !s.ReadBytes(&got, 3) {
@@ -87,8 +89,8 @@ func TestBytes(t *testing.T) {
 			t.Errorf("ReadBytes(): got = %v, want %v", got, want)
 		}
 	}
-	if len(s) != 0 {
-		t.Errorf("len(s) = %d, want 0", len(s))
+	if s.Len() != 0 {
+		t.Errorf("s.Len() = %d, want 0", s.Len())
 	}
 }
 
@@ -99,7 +101,7 @@ func TestUint8(t *testing.T) {
 		t.Error(err)
 	}
 
-	var s String = b.BytesOrPanic()
+	s := NewString(b.BytesOrPanic())
 	var v uint8
 	if !s.ReadUint8(&v) {
 		t.Error("ReadUint8() = false, want true")
@@ -107,8 +109,8 @@ func TestUint8(t *testing.T) {
 	if v != 42 {
 		t.Errorf("v = %d, want 42", v)
 	}
-	if len(s) != 0 {
-		t.Errorf("len(s) = %d, want 0", len(s))
+	if s.Len() != 0 {
+		t.Errorf("s.Len() = %d, want 0", s.Len())
 	}
 }
 
@@ -119,7 +121,7 @@ func TestUint16(t *testing.T) {
 		t.Error(err)
 	}
 
-	var s String = b.BytesOrPanic()
+	s := NewString(b.BytesOrPanic())
 	var v uint16
 	if !s.ReadUint16(&v) {
 		t.Error("ReadUint16() == false, want true")
@@ -127,8 +129,8 @@ func TestUint16(t *testing.T) {
 	if v != 65534 {
 		t.Errorf("v = %d, want 65534", v)
 	}
-	if len(s) != 0 {
-		t.Errorf("len(s) = %d, want 0", len(s))
+	if s.Len() != 0 {
+		t.Errorf("s.Len() = %d, want 0", s.Len())
 	}
 }
 
@@ -139,7 +141,7 @@ func TestUint24(t *testing.T) {
 		t.Error(err)
 	}
 
-	var s String = b.BytesOrPanic()
+	s := NewString(b.BytesOrPanic())
 	var v uint32
 	if !s.ReadUint24(&v) {
 		t.Error("ReadUint8() = false, want true")
@@ -147,8 +149,19 @@ func TestUint24(t *testing.T) {
 	if v != 0xfffefd {
 		t.Errorf("v = %d, want fffefd", v)
 	}
-	if len(s) != 0 {
-		t.Errorf("len(s) = %d, want 0", len(s))
+	if s.Len() != 0 {
+		t.Errorf("s.Len() = %d, want 0", s.Len())
+	}
+}
+
+func TestUint24Range(t *testing.T) {
+	s := NewString([]byte{0xff, 0xff, 0xff, 0xff})
+	v := uint32(0xffffffff)
+	if !s.ReadUint24(&v) {
+		t.Error("ReadUint24() = false, want true")
+	}
+	if v != 1<<24-1 {
+		t.Errorf("v = %x, want ffffff", v)
 	}
 }
 
@@ -167,7 +180,7 @@ func TestUint32(t *testing.T) {
 		t.Error(err)
 	}
 
-	var s String = b.BytesOrPanic()
+	s := NewString(b.BytesOrPanic())
 	var v uint32
 	if !s.ReadUint32(&v) {
 		t.Error("ReadUint8() = false, want true")
@@ -175,8 +188,8 @@ func TestUint32(t *testing.T) {
 	if v != 0xfffefdfc {
 		t.Errorf("v = %x, want fffefdfc", v)
 	}
-	if len(s) != 0 {
-		t.Errorf("len(s) = %d, want 0", len(s))
+	if s.Len() != 0 {
+		t.Errorf("s.Len() = %d, want 0", s.Len())
 	}
 }
 
@@ -189,7 +202,7 @@ func TestUMultiple(t *testing.T) {
 		t.Error(err)
 	}
 
-	var s String = b.BytesOrPanic()
+	s := NewString(b.BytesOrPanic())
 	var (
 		x uint8
 		y uint32
@@ -201,8 +214,8 @@ func TestUMultiple(t *testing.T) {
 	if x != 23 || y != 0xfffefdfc || z != 42 {
 		t.Errorf("x, y, z = %d, %d, %d; want 23, 4294901244, 5", x, y, z)
 	}
-	if len(s) != 0 {
-		t.Errorf("len(s) = %d, want 0", len(s))
+	if s.Len() != 0 {
+		t.Errorf("s.Len() = %d, want 0", s.Len())
 	}
 }
 
@@ -216,7 +229,7 @@ func TestUint8LengthPrefixedSimple(t *testing.T) {
 		t.Error(err)
 	}
 
-	var base, child String = b.BytesOrPanic(), nil
+	base, child := NewString(b.BytesOrPanic()), String{}
 	var x, y uint8
 	if !base.ReadUint8LengthPrefixed(&child) || !child.ReadUint8(&x) ||
 		!child.ReadUint8(&y) {
@@ -225,11 +238,11 @@ func TestUint8LengthPrefixedSimple(t *testing.T) {
 	if x != 23 || y != 42 {
 		t.Errorf("want x, y == 23, 42; got %d, %d", x, y)
 	}
-	if len(base) != 0 {
-		t.Errorf("len(base) = %d, want 0", len(base))
+	if base.Len() != 0 {
+		t.Errorf("base.Len() = %d, want 0", base.Len())
 	}
-	if len(child) != 0 {
-		t.Errorf("len(child) = %d, want 0", len(child))
+	if child.Len() != 0 {
+		t.Errorf("child.Len() = %d, want 0", child.Len())
 	}
 }
 
@@ -248,7 +261,7 @@ func TestUint8LengthPrefixedMulti(t *testing.T) {
 		t.Error(err)
 	}
 
-	var s, child String = b.BytesOrPanic(), nil
+	s, child := NewString(b.BytesOrPanic()), String{}
 	var u, v, w, x, y uint8
 	if !s.ReadUint8LengthPrefixed(&child) || !child.ReadUint8(&u) || !child.ReadUint8(&v) ||
 		!s.ReadUint8(&w) || !s.ReadUint8LengthPrefixed(&child) || !child.ReadUint8(&x) || !child.ReadUint8(&y) {
@@ -258,11 +271,11 @@ func TestUint8LengthPrefixedMulti(t *testing.T) {
 		t.Errorf("u, v, w, x, y = %d, %d, %d, %d, %d; want 23, 42, 5, 123, 234",
 			u, v, w, x, y)
 	}
-	if len(s) != 0 {
-		t.Errorf("len(s) = %d, want 0", len(s))
+	if s.Len() != 0 {
+		t.Errorf("s.Len() = %d, want 0", s.Len())
 	}
-	if len(child) != 0 {
-		t.Errorf("len(child) = %d, want 0", len(child))
+	if child.Len() != 0 {
+		t.Errorf("child.Len() = %d, want 0", child.Len())
 	}
 }
 
@@ -280,7 +293,7 @@ func TestUint8LengthPrefixedNested(t *testing.T) {
 		t.Error(err)
 	}
 
-	var base, child1, child2 String = b.BytesOrPanic(), nil, nil
+	base, child1, child2 := NewString(b.BytesOrPanic()), String{}, String{}
 	var u, v, w, x uint8
 	if !base.ReadUint8LengthPrefixed(&child1) {
 		t.Error("parsing base failed")
@@ -295,14 +308,14 @@ func TestUint8LengthPrefixedNested(t *testing.T) {
 		t.Errorf("u, v, w, x = %d, %d, %d, %d, want 5, 23, 42, 123",
 			u, v, w, x)
 	}
-	if len(base) != 0 {
-		t.Errorf("len(base) = %d, want 0", len(base))
+	if base.Len() != 0 {
+		t.Errorf("base.Len() = %d, want 0", base.Len())
 	}
-	if len(child1) != 0 {
-		t.Errorf("len(child1) = %d, want 0", len(child1))
+	if child1.Len() != 0 {
+		t.Errorf("child1.Len() = %d, want 0", child1.Len())
 	}
-	if len(base) != 0 {
-		t.Errorf("len(child2) = %d, want 0", len(child2))
+	if base.Len() != 0 {
+		t.Errorf("child2.Len() = %d, want 0", child2.Len())
 	}
 }
 
@@ -411,6 +424,35 @@ func TestUnwrite(t *testing.T) {
 		}()
 		b.Unwrite(2) // panics (attempted unwrite while child is pending)
 	})
+
+	b = Builder{}
+	b.AddUint8LengthPrefixed(func(c *Builder) {
+		c.AddBytes([]byte{1, 2, 3})
+	})
+	b.AddBytes([]byte{4, 5})
+	b.Unwrite(2)
+	if err := builderBytesEq(&b, 3, 1, 2, 3); err != nil {
+		t.Error(err)
+	}
+	func() {
+		defer func() {
+			if _, ok := recover().(BuildError); !ok {
+				t.Errorf("recover() is not a BuildError; b.Unwrite() did not panic with BuildError")
+			}
+		}()
+		b.Unwrite(1) // panics (attempted unwrite of a completed child)
+	}()
+
+	b = Builder{}
+	b.AddUint8LengthPrefixed(func(c *Builder) {
+		c.AddUint8LengthPrefixed(func(d *Builder) {
+			d.AddUint8(1)
+		})
+		c.Unwrite(1)
+	})
+	if _, err := b.Bytes(); err == nil {
+		t.Error("Bytes() error = nil, want error for unwriting a completed child")
+	}
 }
 
 func TestFixedBuilderLengthPrefixed(t *testing.T) {
@@ -439,3 +481,1866 @@ func TestFixedBuilderPanicReallocate(t *testing.T) {
 
 	t.Error("Builder did not panic")
 }
+
+func TestUint24LengthPrefixed(t *testing.T) {
+	var b Builder
+	b.AddUint24LengthPrefixed(func(c *Builder) {
+		c.AddUint8(23)
+		c.AddUint8(42)
+	})
+	if err := builderBytesEq(&b, 2, 0, 0, 23, 42); err != nil {
+		t.Error(err)
+	}
+
+	const max = 1<<24 - 1
+	for _, fixed := range []bool{false, true} {
+		for _, n := range []int{max, max + 1} {
+			b := NewBuilder(make([]byte, 0, max+4))
+			if fixed {
+				b = NewFixedBuilder(make([]byte, 0, max+4))
+			}
+			b.AddUint24LengthPrefixed(func(c *Builder) {
+				c.AddZeros(n)
+			})
+			out, err := b.Bytes()
+			if n == max {
+				if err != nil {
+					t.Errorf("fixed=%v: Bytes() error = %v, want nil", fixed, err)
+				} else if !bytes.Equal(out[:3], []byte{0xff, 0xff, 0xff}) {
+					t.Errorf("fixed=%v: prefix = %v, want [255 255 255]", fixed, out[:3])
+				}
+				continue
+			}
+			want := "littlebyte: pending child length 16777216 exceeds 3-byte length prefix at depth 1"
+			if err == nil || err.Error() != want {
+				t.Errorf("fixed=%v: Bytes() error = %v, want %q", fixed, err, want)
+			}
+		}
+	}
+}
+
+func TestBytesRead(t *testing.T) {
+	orig := NewString([]byte{1, 2, 0, 3, 4, 5, 6})
+	s := orig
+	if n := s.BytesRead(); n != 0 {
+		t.Errorf("BytesRead() = %d, want 0", n)
+	}
+	var child String
+	var v uint8
+	if !s.ReadUint8(&v) || !s.ReadUint16LengthPrefixed(&child) {
+		t.Fatal("parsing failed")
+	}
+	if n := s.BytesRead(); n != 5 {
+		t.Errorf("BytesRead() = %d, want 5", n)
+	}
+	if !child.Skip(1) {
+		t.Fatal("Skip(1) = false, want true")
+	}
+	if n := child.BytesRead(); n != 1 {
+		t.Errorf("child.BytesRead() = %d, want 1", n)
+	}
+	if n := child.ConsumedSince(orig); n != 4 {
+		t.Errorf("child.ConsumedSince() = %d, want 4", n)
+	}
+	if !s.Skip(2) {
+		t.Fatal("Skip(2) = false, want true")
+	}
+	if n := s.BytesRead(); n != 7 {
+		t.Errorf("BytesRead() = %d, want 7", n)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("recover() = nil, want error; ConsumedSince() did not panic")
+		}
+	}()
+	NewString([]byte{1}).ConsumedSince(orig) // panics
+}
+
+func TestLen(t *testing.T) {
+	var b Builder
+	b.AddUint16(1)
+	if n := b.Len(); n != 2 {
+		t.Errorf("Len() = %d, want 2", n)
+	}
+	b.AddUint8LengthPrefixed(func(c *Builder) {
+		if n := c.Len(); n != 0 {
+			t.Errorf("c.Len() = %d, want 0", n)
+		}
+		c.AddUint24(1)
+		if n := c.Len(); n != 3 {
+			t.Errorf("c.Len() = %d, want 3", n)
+		}
+	})
+	if n := b.Len(); n != 6 {
+		t.Errorf("Len() = %d, want 6", n)
+	}
+}
+
+func TestReadUint16LengthPrefixedInto(t *testing.T) {
+	s := NewString([]byte{3, 0, 1, 2, 3, 2, 0, 4, 5})
+	dst := make([]byte, 3)
+	n, ok := s.ReadUint16LengthPrefixedInto(dst)
+	if !ok || n != 3 || !bytes.Equal(dst, []byte{1, 2, 3}) {
+		t.Errorf("ReadUint16LengthPrefixedInto() = %d, %v; dst = %v; want 3, true; [1 2 3]", n, ok, dst)
+	}
+	n, ok = s.ReadUint16LengthPrefixedInto(dst[:1])
+	if ok || n != 0 {
+		t.Errorf("ReadUint16LengthPrefixedInto() = %d, %v; want 0, false", n, ok)
+	}
+	if s.Len() != 4 {
+		t.Errorf("s.Len() = %d, want 4", s.Len())
+	}
+	n, ok = s.ReadUint16LengthPrefixedInto(dst)
+	if !ok || n != 2 || !bytes.Equal(dst[:n], []byte{4, 5}) {
+		t.Errorf("ReadUint16LengthPrefixedInto() = %d, %v; dst = %v; want 2, true; [4 5]", n, ok, dst[:n])
+	}
+	if s.Len() != 0 {
+		t.Errorf("s.Len() = %d, want 0", s.Len())
+	}
+}
+
+func benchmarkLengthPrefixedInput() String {
+	var b Builder
+	for i := 0; i < 100; i++ {
+		b.AddUint16LengthPrefixed(func(c *Builder) {
+			c.AddBytes(make([]byte, 64))
+		})
+	}
+	return NewString(b.BytesOrPanic())
+}
+
+func BenchmarkReadUint16LengthPrefixedInto(b *testing.B) {
+	input := benchmarkLengthPrefixedInput()
+	dst := make([]byte, 64)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		s := input
+		for !s.Empty() {
+			if _, ok := s.ReadUint16LengthPrefixedInto(dst); !ok {
+				b.Fatal("ReadUint16LengthPrefixedInto() = false, want true")
+			}
+		}
+	}
+}
+
+func BenchmarkReadUint16LengthPrefixedCopy(b *testing.B) {
+	input := benchmarkLengthPrefixedInput()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		s := input
+		for !s.Empty() {
+			var child String
+			if !s.ReadUint16LengthPrefixed(&child) {
+				b.Fatal("ReadUint16LengthPrefixed() = false, want true")
+			}
+			_ = append([]byte(nil), child.Bytes()...)
+		}
+	}
+}
+
+func TestAlignTo(t *testing.T) {
+	var b Builder
+	b.AlignTo(4, 0xff)
+	b.AddUint8(1)
+	b.AlignTo(4, 0xff)
+	b.AlignTo(4, 0xff)
+	b.AddUint8LengthPrefixed(func(c *Builder) {
+		c.AddUint16(2)
+		c.AlignTo(8, 0)
+	})
+	b.AlignTo(2, 0xee)
+	if err := builderBytesEq(&b, 1, 0xff, 0xff, 0xff, 8, 2, 0, 0, 0, 0, 0, 0, 0, 0xee); err != nil {
+		t.Error(err)
+	}
+
+	orig := NewString(b.BytesOrPanic())
+	s := orig
+	var v uint8
+	if !s.AlignTo(4) || !s.ReadUint8(&v) || !s.AlignTo(4) || !s.AlignTo(4) {
+		t.Fatal("parsing failed")
+	}
+	if n := s.BytesRead(); n != 4 {
+		t.Errorf("BytesRead() = %d, want 4", n)
+	}
+	if !s.Skip(9) || s.AlignTo(4) {
+		t.Error("AlignTo() = true past the end, want false")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("recover() = nil, want error; AlignTo() did not panic")
+		}
+	}()
+	b.AlignTo(3, 0) // panics
+}
+
+func TestAddZerosAndRepeated(t *testing.T) {
+	var b Builder
+	b.AddUint8(1)
+	b.AddZeros(3)
+	b.AddUint8LengthPrefixed(func(c *Builder) {
+		c.AddRepeated(0xab, 2)
+		c.AddZeros(0)
+	})
+	b.AddRepeated(7, 1)
+	if err := builderBytesEq(&b, 1, 0, 0, 0, 2, 0xab, 0xab, 7); err != nil {
+		t.Error(err)
+	}
+
+	// Bytes previously unwritten must be cleared.
+	b = Builder{}
+	b.AddBytes([]byte{1, 2, 3, 4})
+	b.Unwrite(3)
+	b.AddZeros(2)
+	if err := builderBytesEq(&b, 1, 0, 0); err != nil {
+		t.Error(err)
+	}
+
+	b = *NewFixedBuilder(make([]byte, 0, 4))
+	b.AddRepeated(0xff, 4)
+	if err := builderBytesEq(&b, 0xff, 0xff, 0xff, 0xff); err != nil {
+		t.Error(err)
+	}
+	b.AddZeros(1)
+	if _, err := b.Bytes(); err == nil {
+		t.Error("Bytes() error = nil, want error for exceeding fixed-size buffer")
+	}
+}
+
+func TestReadIf(t *testing.T) {
+	s := NewString([]byte{4, 1, 2, 3, 4, 0})
+	var flags uint8
+	var extra uint32
+	readExtra := func(s *String) bool {
+		return s.ReadUint32(&extra)
+	}
+	if !s.ReadUint8(&flags) || !s.ReadIf(flags&4 != 0, readExtra) {
+		t.Fatal("parsing failed")
+	}
+	if extra != 0x04030201 {
+		t.Errorf("extra = %#x, want 0x04030201", extra)
+	}
+
+	extra = 0
+	if !s.ReadUint8(&flags) || !s.ReadIf(flags&4 != 0, readExtra) {
+		t.Fatal("parsing failed")
+	}
+	if extra != 0 {
+		t.Errorf("extra = %#x, want 0", extra)
+	}
+	if s.Len() != 0 {
+		t.Errorf("s.Len() = %d, want 0", s.Len())
+	}
+
+	if s.ReadIf(true, readExtra) {
+		t.Error("ReadIf(true) = true on empty input, want false")
+	}
+}
+
+func TestConsumedSince(t *testing.T) {
+	s := NewString([]byte{5, 0, 1, 2, 3, 9})
+	var declared uint16
+	if !s.ReadUint16(&declared) {
+		t.Fatal("ReadUint16() = false, want true")
+	}
+	start := s
+	var x uint8
+	var y uint16
+	if !s.ReadUint8(&x) || !s.ReadUint16(&y) {
+		t.Fatal("parsing failed")
+	}
+	if n := s.ConsumedSince(start); n != 3 {
+		t.Errorf("ConsumedSince() = %d, want 3", n)
+	}
+	// The parser above under-reads the message.
+	if s.ConsumedExactly(start, int(declared)) {
+		t.Error("ConsumedExactly() = true, want false")
+	}
+	if !s.Skip(1) || !s.ConsumedExactly(start, 4) {
+		t.Error("ConsumedExactly(4) = false, want true")
+	}
+}
+
+func TestReadArrays(t *testing.T) {
+	s := NewString([]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15})
+	var u8 [3]uint8
+	var u16 [2]uint16
+	var u32 [2]uint32
+	if !s.ReadUint8Array(u8[:]) || !s.ReadUint16Array(u16[:]) || !s.ReadUint32Array(u32[:]) {
+		t.Fatal("parsing failed")
+	}
+	if u8 != [3]uint8{1, 2, 3} || u16 != [2]uint16{0x0504, 0x0706} || u32 != [2]uint32{0x0b0a0908, 0x0f0e0d0c} {
+		t.Errorf("u8, u16, u32 = %x, %x, %x", u8, u16, u32)
+	}
+	if s.Len() != 0 {
+		t.Errorf("s.Len() = %d, want 0", s.Len())
+	}
+
+	s = NewString([]byte{1, 2, 3, 4, 5, 6, 7})
+	u16 = [2]uint16{}
+	u32 = [2]uint32{}
+	if s.ReadUint32Array(u32[:]) {
+		t.Error("ReadUint32Array() = true, want false")
+	}
+	if u32 != [2]uint32{} {
+		t.Errorf("u32 = %x, want it unmodified", u32)
+	}
+	s.Skip(4)
+	if s.ReadUint16Array(u16[:]) {
+		t.Error("ReadUint16Array() = true, want false")
+	}
+	if u16 != [2]uint16{} {
+		t.Errorf("u16 = %x, want it unmodified", u16)
+	}
+	if s.Len() != 3 {
+		t.Errorf("s.Len() = %d, want 3", s.Len())
+	}
+}
+
+func TestAddSlices(t *testing.T) {
+	var b Builder
+	b.AddUint16Slice([]uint16{0x0201, 0x0403})
+	b.AddUint32Slice([]uint32{0x08070605})
+	b.AddUint16Slice(nil)
+	if err := builderBytesEq(&b, 1, 2, 3, 4, 5, 6, 7, 8); err != nil {
+		t.Error(err)
+	}
+
+	b = *NewFixedBuilder(make([]byte, 0, 7))
+	b.AddUint32Slice([]uint32{1, 2})
+	if _, err := b.Bytes(); err == nil {
+		t.Error("Bytes() error = nil, want error for exceeding fixed-size buffer")
+	}
+}
+
+var benchmarkSamples = make([]uint16, 4096)
+
+func BenchmarkAddUint16Slice(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var bb Builder
+		bb.AddUint16Slice(benchmarkSamples)
+	}
+}
+
+func BenchmarkAddUint16Loop(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var bb Builder
+		for _, v := range benchmarkSamples {
+			bb.AddUint16(v)
+		}
+	}
+}
+
+func TestAddFixedRecord(t *testing.T) {
+	var b Builder
+	b.AddUint8(0xff)
+	b.AddFixedRecord(64, func(b *Builder) {
+		b.AddBytes(bytes.Repeat([]byte{1}, 40))
+	})
+	b.AddUint8(0xff)
+	want := append(append([]byte{0xff}, bytes.Repeat([]byte{1}, 40)...), make([]byte, 24)...)
+	want = append(want, 0xff)
+	if err := builderBytesEq(&b, want...); err != nil {
+		t.Error(err)
+	}
+
+	b = Builder{}
+	b.AddFixedRecord(64, func(b *Builder) {
+		b.AddBytes(make([]byte, 70))
+	})
+	if _, err := b.Bytes(); err == nil {
+		t.Error("Bytes() error = nil, want error for oversized record")
+	}
+}
+
+func TestUint16ElementPrefixed(t *testing.T) {
+	var b Builder
+	b.AddUint16ElementPrefixed(4, func(c *Builder) {
+		c.AddUint32Slice([]uint32{1, 2, 3})
+	})
+	b.AddUint8(0xff)
+	if err := builderBytesEq(&b, 3, 0, 1, 0, 0, 0, 2, 0, 0, 0, 3, 0, 0, 0, 0xff); err != nil {
+		t.Error(err)
+	}
+
+	s := NewString(b.BytesOrPanic())
+	var child String
+	if !s.ReadUint16ElementPrefixed(&child, 4) {
+		t.Fatal("ReadUint16ElementPrefixed() = false, want true")
+	}
+	if child.Len() != 12 {
+		t.Errorf("child.Len() = %d, want 12", child.Len())
+	}
+	if s.Len() != 1 {
+		t.Errorf("s.Len() = %d, want 1", s.Len())
+	}
+
+	s = NewString([]byte{2, 0, 1, 2, 3})
+	if s.ReadUint16ElementPrefixed(&child, 2) {
+		t.Error("ReadUint16ElementPrefixed() = true on truncated input, want false")
+	}
+	s = NewString([]byte{2, 0, 1, 2, 3})
+	if s.ReadUint16ElementPrefixed(&child, maxInt/2+1) {
+		t.Error("ReadUint16ElementPrefixed() = true on overflowing length, want false")
+	}
+
+	b = Builder{}
+	b.AddUint16ElementPrefixed(4, func(c *Builder) {
+		c.AddUint16(1)
+	})
+	if _, err := b.Bytes(); err == nil {
+		t.Error("Bytes() error = nil, want error for partial element")
+	}
+}
+
+func TestHex(t *testing.T) {
+	s := NewString([]byte("\x01\x02hello, world!\xff"))
+	s.Skip(1)
+	if got, want := s.Hex(), "0268656c6c6f2c20776f726c6421ff"; got != want {
+		t.Errorf("Hex() = %q, want %q", got, want)
+	}
+	want := "00000000  02 68 65 6c 6c 6f 2c 20  77 6f 72 6c 64 21 ff     |.hello, world!.|\n"
+	if got := s.HexDump(); got != want {
+		t.Errorf("HexDump() = %q, want %q", got, want)
+	}
+}
+
+func TestClone(t *testing.T) {
+	b := NewBuilder(make([]byte, 0, 64))
+	b.AddUint8LengthPrefixed(func(c *Builder) {
+		c.AddUint16(1)
+	})
+	c := b.Clone()
+	b.AddUint8(2)
+	c.AddUint8(3)
+	if err := builderBytesEq(b, 2, 1, 0, 2); err != nil {
+		t.Error(err)
+	}
+	if err := builderBytesEq(c, 2, 1, 0, 3); err != nil {
+		t.Error(err)
+	}
+
+	// Completed length-prefixed values in the clone cannot be unwritten.
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("recover() = nil, want error; Unwrite() on clone did not panic")
+			}
+		}()
+		b.Clone().Unwrite(2)
+	}()
+	c.Unwrite(1)
+	if err := builderBytesEq(c, 2, 1, 0); err != nil {
+		t.Error(err)
+	}
+
+	b = NewFixedBuilder(make([]byte, 0, 2))
+	b.AddUint8(1)
+	c = b.Clone()
+	c.AddUint16(2)
+	if _, err := c.Bytes(); err == nil {
+		t.Error("Bytes() error = nil, want error for exceeding fixed-size buffer")
+	}
+	if c = c.Clone(); c.err == nil {
+		t.Error("Clone() did not copy error")
+	}
+
+	b = &Builder{}
+	b.AddUint8LengthPrefixed(func(child *Builder) {
+		child.AddUint8(1)
+		c := child.Clone()
+		if err := builderBytesEq(c, 1); err != nil {
+			t.Error(err)
+		}
+
+		defer func() {
+			if recover() == nil {
+				t.Errorf("recover() = nil, want error; Clone() did not panic")
+			}
+		}()
+		b.Clone() // panics (child is pending)
+	})
+}
+
+func TestReadUint16LengthPrefixedFill(t *testing.T) {
+	s := NewString([]byte{3, 0, 1, 2, 3, 3, 0, 4, 5, 6, 0xff})
+	var values []String
+	if !s.ReadUint16LengthPrefixedFill(&values, 10) {
+		t.Fatal("ReadUint16LengthPrefixedFill() = false, want true")
+	}
+	if len(values) != 2 || !bytes.Equal(values[0].Bytes(), []byte{1, 2, 3}) || !bytes.Equal(values[1].Bytes(), []byte{4, 5, 6}) {
+		t.Errorf("values = %v, want [[1 2 3] [4 5 6]]", values)
+	}
+	if s.Len() != 1 {
+		t.Errorf("s.Len() = %d, want 1", s.Len())
+	}
+
+	// The second value overruns the budget.
+	s = NewString([]byte{3, 0, 1, 2, 3, 3, 0, 4, 5, 6, 0xff})
+	values = nil
+	if s.ReadUint16LengthPrefixedFill(&values, 9) {
+		t.Error("ReadUint16LengthPrefixedFill() = true, want false")
+	}
+	if values != nil {
+		t.Errorf("values = %v, want nil", values)
+	}
+	if s.Len() != 11 {
+		t.Errorf("s.Len() = %d after failure, want 11", s.Len())
+	}
+
+	// The region itself is short.
+	s = NewString([]byte{3, 0, 1, 2})
+	if s.ReadUint16LengthPrefixedFill(&values, 5) {
+		t.Error("ReadUint16LengthPrefixedFill() = true for short input, want false")
+	}
+	if s.Len() != 4 {
+		t.Errorf("s.Len() = %d after failure, want 4", s.Len())
+	}
+}
+
+func TestStringClone(t *testing.T) {
+	s := NewString([]byte{1, 2, 3})
+	c := s.Clone()
+	var v uint32
+	if c.ReadUint32(&v) {
+		t.Fatal("ReadUint32() = true, want false")
+	}
+	var x uint16
+	if !c.ReadUint16(&x) || c.Len() != 1 {
+		t.Fatal("parsing clone failed")
+	}
+	if s.Len() != 3 {
+		t.Errorf("s.Len() = %d, want 3", s.Len())
+	}
+	if &s.Bytes()[2] != &c.Bytes()[0] {
+		t.Error("clone does not share bytes with s")
+	}
+}
+
+func TestStats(t *testing.T) {
+	b := NewBuilder(make([]byte, 0, 8))
+	b.AddUint32(1)
+	b.AddUint8LengthPrefixed(func(c *Builder) {
+		c.AddUint16(2)
+		c.AddUint8LengthPrefixed(func(d *Builder) {})
+		if got, want := b.Stats(), (BuilderStats{BytesWritten: 8, ChildrenOpened: 2}); got != want {
+			t.Errorf("Stats() = %+v, want %+v", got, want)
+		}
+		c.AddUint8(3) // Outgrow the initial capacity.
+	})
+	b.AddUint8(4)
+	b.Unwrite(1)
+	want := BuilderStats{
+		BytesWritten:   10,
+		Reallocations:  1,
+		ChildrenOpened: 2,
+	}
+	if got := b.Stats(); got != want {
+		t.Errorf("Stats() = %+v, want %+v", got, want)
+	}
+}
+
+func TestReadRemaining(t *testing.T) {
+	s := NewString([]byte{1, 2, 3})
+	var v uint8
+	var rest []byte
+	if !s.ReadUint8(&v) {
+		t.Fatal("ReadUint8() = false, want true")
+	}
+	if got := s.Rest(); !bytes.Equal(got, []byte{2, 3}) || s.Len() != 2 {
+		t.Errorf("Rest() = %v, s.Len() = %d; want [2 3], 2", got, s.Len())
+	}
+	s.ReadRemaining(&rest)
+	if !bytes.Equal(rest, []byte{2, 3}) {
+		t.Errorf("ReadRemaining(): got %v, want [2 3]", rest)
+	}
+	if !s.Empty() {
+		t.Errorf("s.Len() = %d, want 0", s.Len())
+	}
+	s.ReadRemaining(&rest)
+	if len(rest) != 0 {
+		t.Errorf("ReadRemaining(): got %v, want []", rest)
+	}
+}
+
+func TestUint8LengthPrefixedUint64(t *testing.T) {
+	for _, test := range []struct {
+		v    uint64
+		want []byte
+	}{
+		{0, []byte{0}},
+		{255, []byte{1, 0xff}},
+		{256, []byte{2, 0, 1}},
+		{0x0102030405060708, []byte{8, 8, 7, 6, 5, 4, 3, 2, 1}},
+	} {
+		var b Builder
+		b.AddUint8LengthPrefixedUint64(test.v)
+		if err := builderBytesEq(&b, test.want...); err != nil {
+			t.Error(err)
+		}
+		s := NewString(b.BytesOrPanic())
+		var v uint64 = 42
+		if !s.ReadUint8LengthPrefixedUint64(&v) || v != test.v || s.Len() != 0 {
+			t.Errorf("ReadUint8LengthPrefixedUint64(): v = %#x, s.Len() = %d; want %#x, 0", v, s.Len(), test.v)
+		}
+	}
+
+	s := NewString([]byte{9, 1, 2, 3, 4, 5, 6, 7, 8, 9})
+	var v uint64
+	if s.ReadUint8LengthPrefixedUint64(&v) {
+		t.Error("ReadUint8LengthPrefixedUint64() = true for 9-byte value, want false")
+	}
+}
+
+func TestPeekLengthPrefixed(t *testing.T) {
+	for _, test := range []struct {
+		peek  func(String, *String) bool
+		input []byte
+	}{
+		{String.PeekUint8LengthPrefixed, []byte{2, 5, 6, 7}},
+		{String.PeekUint16LengthPrefixed, []byte{2, 0, 5, 6, 7}},
+		{String.PeekUint24LengthPrefixed, []byte{2, 0, 0, 5, 6, 7}},
+		{String.PeekUint32LengthPrefixed, []byte{2, 0, 0, 0, 5, 6, 7}},
+	} {
+		s := NewString(test.input)
+		var child String
+		if !test.peek(s, &child) {
+			t.Errorf("%v: Peek() = false, want true", test.input)
+			continue
+		}
+		if !bytes.Equal(child.Bytes(), []byte{5, 6}) {
+			t.Errorf("%v: child = %v, want [5 6]", test.input, child.Bytes())
+		}
+		if s.Len() != len(test.input) {
+			t.Errorf("%v: s.Len() = %d, want %d", test.input, s.Len(), len(test.input))
+		}
+		s = NewString(test.input[:len(test.input)-2])
+		if test.peek(s, &child) {
+			t.Errorf("%v: Peek() = true on truncated input, want false", test.input)
+		}
+	}
+}
+
+func TestReason(t *testing.T) {
+	s := NewString([]byte{1, 2, 1, 5, 6})
+	var (
+		x uint16
+		y uint32
+		z uint8
+	)
+	if s.Reason() != "" {
+		t.Errorf("Reason() = %q, want empty", s.Reason())
+	}
+	ok := s.ReadUint16(&x) && s.ReadUint32(&y) && s.ReadUint8(&z)
+	if ok {
+		t.Fatal("parsing succeeded, want failure")
+	}
+	// Later failures do not replace the reason for the first.
+	s.Skip(4)
+	const want = "littlebyte: ReadUint32 failed with 3 bytes remaining"
+	if got := s.Reason(); got != want {
+		t.Errorf("Reason() = %q, want %q", got, want)
+	}
+	if !bytes.Equal(s.Bytes(), []byte{1, 5, 6}) {
+		t.Errorf("Bytes() = %v, want [1 5 6]", s.Bytes())
+	}
+
+	// A String read from another does not inherit its reason.
+	var child String
+	if !s.ReadUint8LengthPrefixed(&child) || child.Reason() != "" {
+		t.Errorf("child.Reason() = %q, want empty", child.Reason())
+	}
+}
+
+func TestReadOptional(t *testing.T) {
+	s := NewString([]byte{1, 0x34, 0x12, 0, 2})
+	var present bool
+	var v uint16
+	readValue := func(s *String) bool {
+		return s.ReadUint16(&v)
+	}
+	if !s.ReadOptional(&present, readValue) || !present || v != 0x1234 {
+		t.Errorf("ReadOptional(): present, v = %v, %#x; want true, 0x1234", present, v)
+	}
+	v = 0
+	if !s.ReadOptional(&present, readValue) || present || v != 0 {
+		t.Errorf("ReadOptional(): present, v = %v, %#x; want false, 0", present, v)
+	}
+	if s.ReadOptional(&present, readValue) {
+		t.Error("ReadOptional() = true for invalid flag, want false")
+	}
+	s = NewString([]byte{1, 0})
+	if s.ReadOptional(&present, readValue) {
+		t.Error("ReadOptional() = true on truncated value, want false")
+	}
+}
+
+func TestReserve(t *testing.T) {
+	b := NewBuilder(make([]byte, 0, 4))
+	b.AddUint8(1)
+	patch := b.Reserve(2)
+	b.AddUint8LengthPrefixed(func(c *Builder) {
+		c.AddBytes(make([]byte, 8)) // Outgrow the initial buffer.
+		patchChild := c.Reserve(1)
+		patchChild([]byte{0xcc})
+	})
+	patch([]byte{0xaa, 0xbb})
+	if err := builderBytesEq(b, 1, 0xaa, 0xbb, 9, 0, 0, 0, 0, 0, 0, 0, 0, 0xcc); err != nil {
+		t.Error(err)
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("recover() = nil, want error; patch() did not panic")
+			}
+		}()
+		patch([]byte{1}) // panics (wrong length)
+	}()
+
+	b = &Builder{}
+	patch = b.Reserve(2)
+	b.AddUint8LengthPrefixed(func(c *Builder) {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("recover() = nil, want error; patch() did not panic")
+			}
+		}()
+		patch([]byte{1, 2}) // panics (child is pending)
+	})
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("recover() = nil, want error; patch() did not panic")
+		}
+	}()
+	b = &Builder{}
+	patch = b.Reserve(2)
+	b.Unwrite(1)
+	patch([]byte{1, 2}) // panics (unwritten)
+}
+
+func TestCopyBytes(t *testing.T) {
+	input := []byte{1, 2, 3, 4, 5, 6, 7}
+	s := NewString(input)
+	var mac [6]byte
+	if !s.CopyBytes(mac[:]) {
+		t.Fatal("CopyBytes() = false, want true")
+	}
+	if mac != [6]byte{1, 2, 3, 4, 5, 6} {
+		t.Errorf("mac = %v, want [1 2 3 4 5 6]", mac)
+	}
+	input[0] = 0xff
+	if mac[0] != 1 {
+		t.Error("CopyBytes() result aliases the input")
+	}
+	if s.CopyBytes(mac[:]) {
+		t.Error("CopyBytes() = true on short input, want false")
+	}
+	if mac != [6]byte{1, 2, 3, 4, 5, 6} {
+		t.Errorf("mac = %v, want it unmodified", mac)
+	}
+	if s.Len() != 1 {
+		t.Errorf("s.Len() = %d, want 1", s.Len())
+	}
+}
+
+func TestGrow(t *testing.T) {
+	var b Builder
+	b.AddUint8(1)
+	b.Grow(100)
+	if c := cap(b.result); c < 101 {
+		t.Errorf("cap = %d, want at least 101", c)
+	}
+	for i := 0; i < 100; i++ {
+		b.AddUint8(2)
+	}
+	if n := b.Stats().Reallocations; n != 2 {
+		t.Errorf("Stats().Reallocations = %d, want 2", n)
+	}
+	if b.Len() != 101 || b.BytesOrPanic()[0] != 1 {
+		t.Error("Grow() did not preserve contents")
+	}
+
+	buf := make([]byte, 0, 4)
+	f := NewFixedBuilder(buf)
+	f.Grow(10)
+	f.AddUint32(1)
+	if got := f.BytesOrPanic(); &got[0] != &buf[:1][0] {
+		t.Error("Grow() reallocated a fixed-size buffer")
+	}
+}
+
+func BenchmarkBuildWithGrow(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var bb Builder
+		bb.Grow(4096)
+		for j := 0; j < 1024; j++ {
+			bb.AddUint32(uint32(j))
+		}
+	}
+}
+
+func BenchmarkBuildWithoutGrow(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var bb Builder
+		for j := 0; j < 1024; j++ {
+			bb.AddUint32(uint32(j))
+		}
+	}
+}
+
+func TestAddString(t *testing.T) {
+	var b Builder
+	b.AddUint8LengthPrefixed(func(c *Builder) {
+		c.AddString("hello")
+	})
+	b.AddString("")
+	if err := builderBytesEq(&b, 5, 'h', 'e', 'l', 'l', 'o'); err != nil {
+		t.Error(err)
+	}
+
+	fixed := NewFixedBuilder(make([]byte, 0, 4))
+	fixed.AddString("hello")
+	if _, err := fixed.Bytes(); err == nil {
+		t.Error("Bytes() error = nil, want error for exceeding fixed-size buffer")
+	}
+
+	name := strings.Repeat("x", 64)
+	buf := NewBuilder(make([]byte, 0, 64))
+	allocs := testing.AllocsPerRun(100, func() {
+		buf.Unwrite(buf.Len())
+		buf.AddString(name)
+	})
+	if allocs != 0 {
+		t.Errorf("AddString() allocated %v times, want 0", allocs)
+	}
+}
+
+func TestReadUint32LengthPrefixedLimit(t *testing.T) {
+	s := NewString([]byte{3, 0, 0, 0, 1, 2, 3, 4})
+	var v String
+	if !s.ReadUint32LengthPrefixedLimit(&v, 3) {
+		t.Fatal("ReadUint32LengthPrefixedLimit() = false, want true")
+	}
+	if got, want := v.Bytes(), []byte{1, 2, 3}; !bytes.Equal(got, want) {
+		t.Errorf("ReadUint32LengthPrefixedLimit() read %v, want %v", got, want)
+	}
+	if got, want := s.Bytes(), []byte{4}; !bytes.Equal(got, want) {
+		t.Errorf("remaining = %v, want %v", got, want)
+	}
+
+	// The declared length is rejected before the content is examined.
+	s = NewString([]byte{0xff, 0xff, 0xff, 0xff, 1})
+	if s.ReadUint32LengthPrefixedLimit(&v, 1<<20) {
+		t.Error("ReadUint32LengthPrefixedLimit() = true, want false")
+	}
+	if got, want := s.Len(), 5; got != want {
+		t.Errorf("Len() = %d, want %d", got, want)
+	}
+	if got, want := s.Reason(), "ReadUint32LengthPrefixedLimit"; !strings.Contains(got, want) {
+		t.Errorf("Reason() = %q, want it to mention %q", got, want)
+	}
+
+	s = NewString([]byte{2, 0, 0, 0, 1})
+	if s.ReadUint32LengthPrefixedLimit(&v, 2) {
+		t.Error("ReadUint32LengthPrefixedLimit() = true for short input, want false")
+	}
+}
+
+func TestStrictLengthChecks(t *testing.T) {
+	overflow := func(c *Builder) {
+		c.AddZeros(256)
+	}
+	const want = "littlebyte: pending child length 256 exceeds 1-byte length prefix at depth 2"
+
+	for _, nested := range []bool{false, true} {
+		want := want
+		if !nested {
+			want = "littlebyte: pending child length 256 exceeds 1-byte length prefix at depth 1"
+		}
+		func() {
+			defer func() {
+				r := recover()
+				if err, ok := r.(BuildError); !ok || err.Err.Error() != want {
+					t.Errorf("nested=%v: recover() = %v, want BuildError %q", nested, r, want)
+				}
+			}()
+			var b Builder
+			b.SetStrictLengthChecks(true)
+			if nested {
+				b.AddUint16LengthPrefixed(func(c *Builder) {
+					c.AddUint8LengthPrefixed(overflow)
+					t.Errorf("nested=%v: continuation continued after overflow", nested)
+				})
+			} else {
+				b.AddUint8LengthPrefixed(overflow)
+			}
+			t.Errorf("nested=%v: AddUint8LengthPrefixed() did not panic", nested)
+		}()
+	}
+
+	// Other BuildErrors are still recovered in strict mode.
+	var b Builder
+	b.SetStrictLengthChecks(true)
+	b.AddUint8LengthPrefixed(func(c *Builder) {
+		c.AddUint8LengthPrefixed(func(d *Builder) {
+			panic(BuildError{Err: errors.New("other")})
+		})
+	})
+	if _, err := b.Bytes(); err == nil || err.Error() != "other" {
+		t.Errorf("Bytes() error = %v, want %q", err, "other")
+	}
+
+	// Without strict mode, the overflow is reported by Bytes.
+	b = Builder{}
+	b.AddUint16LengthPrefixed(func(c *Builder) {
+		c.AddUint8LengthPrefixed(overflow)
+	})
+	if _, err := b.Bytes(); err == nil || err.Error() != want {
+		t.Errorf("Bytes() error = %v, want %q", err, want)
+	}
+}
+
+func TestAddUint16LengthPrefixedLen(t *testing.T) {
+	var b Builder
+	b.AddUint8(1)
+	var n1, n2 int
+	n1 = b.AddUint16LengthPrefixedLen(func(c *Builder) {
+		c.AddUint24(2)
+		n2 = c.AddUint16LengthPrefixedLen(func(d *Builder) {})
+	})
+	if n1 != 5 || n2 != 0 {
+		t.Errorf("AddUint16LengthPrefixedLen() = %d, %d; want 5, 0", n1, n2)
+	}
+	if err := builderBytesEq(&b, 1, 5, 0, 2, 0, 0, 0, 0); err != nil {
+		t.Error(err)
+	}
+
+	b = Builder{}
+	b.SetError(errors.New("error"))
+	if n := b.AddUint16LengthPrefixedLen(func(c *Builder) {}); n != 0 {
+		t.Errorf("AddUint16LengthPrefixedLen() = %d after error, want 0", n)
+	}
+}
+
+func TestUint(t *testing.T) {
+	var b Builder
+	for width := 1; width <= 8; width++ {
+		b.AddUint(0x0807060504030201, width)
+	}
+	s := NewString(b.BytesOrPanic())
+	if got, want := s.Len(), 36; got != want {
+		t.Fatalf("Len() = %d, want %d", got, want)
+	}
+	for width := 1; width <= 8; width++ {
+		var v uint64
+		if !s.ReadUint(&v, width) {
+			t.Fatalf("ReadUint(%d) = false, want true", width)
+		}
+		if want := uint64(0x0807060504030201) & (^uint64(0) >> uint(64-8*width)); v != want {
+			t.Errorf("ReadUint(%d) = %#x, want %#x", width, v, want)
+		}
+	}
+	var v uint64
+	if s.ReadUint(&v, 1) {
+		t.Error("ReadUint() = true on empty input, want false")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("recover() = nil, want error; AddUint() did not panic")
+		}
+	}()
+	b.AddUint(0, 9)
+}
+
+func TestTruncate(t *testing.T) {
+	orig := NewString([]byte{1, 2, 3, 4, 5, 6})
+	body := orig
+	if !body.Truncate(4) {
+		t.Fatal("Truncate(4) = false, want true")
+	}
+	var v uint32
+	if !body.Skip(1) || body.ReadUint32(&v) {
+		t.Error("ReadUint32() read past the truncated end")
+	}
+	if got := body.BytesRead(); got != 1 {
+		t.Errorf("BytesRead() = %d, want 1", got)
+	}
+
+	if body.Truncate(4) || body.Truncate(-1) {
+		t.Error("Truncate() = true beyond the String's length, want false")
+	}
+	if body.Len() != 3 {
+		t.Errorf("Len() = %d after failed Truncate, want 3", body.Len())
+	}
+}
+
+func TestSplit(t *testing.T) {
+	s := NewString([]byte{2, 0, 3, 4, 5})
+	head, ok := s.Split(2)
+	if !ok {
+		t.Fatal("Split(2) = false, want true")
+	}
+	var v uint16
+	if !head.ReadUint16(&v) || v != 2 || !head.Empty() {
+		t.Errorf("head.ReadUint16() = %d, want 2 with head empty", v)
+	}
+	if got, want := s.Bytes(), []byte{3, 4, 5}; !bytes.Equal(got, want) {
+		t.Errorf("remaining = %v, want %v", got, want)
+	}
+
+	if _, ok := s.Split(4); ok {
+		t.Error("Split(4) = true on short input, want false")
+	}
+	if s.Len() != 3 {
+		t.Errorf("Len() = %d after failed Split, want 3", s.Len())
+	}
+}
+
+func TestAddBytesFrom(t *testing.T) {
+	var b Builder
+	b.AddUint8LengthPrefixed(func(c *Builder) {
+		if err := c.AddBytesFrom(strings.NewReader("hello, world"), 5); err != nil {
+			t.Errorf("AddBytesFrom() = %v, want nil", err)
+		}
+	})
+	if err := builderBytesEq(&b, 5, 'h', 'e', 'l', 'l', 'o'); err != nil {
+		t.Error(err)
+	}
+
+	b = Builder{}
+	if err := b.AddBytesFrom(strings.NewReader("abc"), 4); err != io.ErrUnexpectedEOF {
+		t.Errorf("AddBytesFrom() = %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+	if _, err := b.Bytes(); err != io.ErrUnexpectedEOF {
+		t.Errorf("Bytes() error = %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+
+	fixed := NewFixedBuilder(make([]byte, 0, 4))
+	if err := fixed.AddBytesFrom(strings.NewReader("hello"), 5); err == nil {
+		t.Error("AddBytesFrom() = nil, want error for exceeding fixed-size buffer")
+	}
+}
+
+func TestPendingDepth(t *testing.T) {
+	var b Builder
+	if n := b.PendingDepth(); n != 0 {
+		t.Errorf("PendingDepth() = %d, want 0", n)
+	}
+	b.AddUint8LengthPrefixed(func(c *Builder) {
+		if n := b.PendingDepth(); n != 1 {
+			t.Errorf("PendingDepth() = %d, want 1", n)
+		}
+		c.AddUint16LengthPrefixed(func(d *Builder) {
+			if n := b.PendingDepth(); n != 2 {
+				t.Errorf("PendingDepth() = %d, want 2", n)
+			}
+			if n := c.PendingDepth(); n != 1 {
+				t.Errorf("c.PendingDepth() = %d, want 1", n)
+			}
+			if n := d.PendingDepth(); n != 0 {
+				t.Errorf("d.PendingDepth() = %d, want 0", n)
+			}
+		})
+	})
+	if n := b.PendingDepth(); n != 0 {
+		t.Errorf("PendingDepth() = %d after continuations returned, want 0", n)
+	}
+}
+
+func TestMustEmpty(t *testing.T) {
+	s := NewString([]byte{1, 2, 3})
+	if err := s.MustEmpty(); err == nil || !strings.Contains(err.Error(), "3 unexpected trailing bytes") {
+		t.Errorf("MustEmpty() = %v, want error reporting 3 trailing bytes", err)
+	}
+	s.Skip(3)
+	if err := s.MustEmpty(); err != nil {
+		t.Errorf("MustEmpty() = %v, want nil", err)
+	}
+}
+
+func TestTryAdd(t *testing.T) {
+	b := NewFixedBuilder(make([]byte, 0, 10))
+	if err := b.TryAddUint8(1); err != nil {
+		t.Errorf("TryAddUint8() = %v, want nil", err)
+	}
+	if err := b.TryAddUint16(2); err != nil {
+		t.Errorf("TryAddUint16() = %v, want nil", err)
+	}
+	if err := b.TryAddUint24(3); err != nil {
+		t.Errorf("TryAddUint24() = %v, want nil", err)
+	}
+	if err := b.TryAddUint32(4); err != nil {
+		t.Errorf("TryAddUint32() = %v, want nil", err)
+	}
+	if err := builderBytesEq(b, 1, 2, 0, 3, 0, 0, 4, 0, 0, 0); err != nil {
+		t.Error(err)
+	}
+	if err := b.TryAddUint8(5); err == nil {
+		t.Error("TryAddUint8() = nil, want error for exceeding fixed-size buffer")
+	}
+	if err := b.TryAddUint32(6); err == nil {
+		t.Error("TryAddUint32() = nil after earlier error, want error")
+	}
+}
+
+func TestAddUint16LengthPrefixedMax(t *testing.T) {
+	var b Builder
+	b.AddUint16LengthPrefixedMax(3, func(c *Builder) {
+		c.AddUint24(1)
+	})
+	if err := builderBytesEq(&b, 3, 0, 1, 0, 0); err != nil {
+		t.Error(err)
+	}
+
+	b = Builder{}
+	b.AddUint16LengthPrefixedMax(3, func(c *Builder) {
+		c.AddUint32(1)
+	})
+	const want = "littlebyte: pending child length 4 exceeds maximum of 3"
+	if _, err := b.Bytes(); err == nil || err.Error() != want {
+		t.Errorf("Bytes() error = %v, want %q", err, want)
+	}
+}
+
+func TestUnread(t *testing.T) {
+	s := NewString([]byte{1, 2, 3, 4})
+	var v uint8
+	if !s.ReadUint8(&v) || !s.ReadUint8(&v) {
+		t.Fatal("ReadUint8() = false, want true")
+	}
+	if !s.Unread(1) {
+		t.Fatal("Unread(1) = false, want true")
+	}
+	if !s.ReadUint8(&v) || v != 2 {
+		t.Errorf("ReadUint8() after Unread = %d, want 2", v)
+	}
+	if s.Unread(3) || s.Unread(-1) {
+		t.Error("Unread() = true beyond the consumed bytes, want false")
+	}
+	if !s.Unread(2) || s.Len() != 4 {
+		t.Errorf("Unread(2): Len() = %d, want 4", s.Len())
+	}
+
+	// Content cannot be moved back into its length prefix.
+	s = NewString([]byte{2, 5, 6})
+	var child String
+	if !s.ReadUint8LengthPrefixed(&child) || !child.ReadUint8(&v) {
+		t.Fatal("parsing failed")
+	}
+	if !child.Unread(1) || child.Unread(1) {
+		t.Error("child.Unread() moved back past the start of the child")
+	}
+
+	// The limit of a truncated String is preserved.
+	s = NewString([]byte{1, 2, 3, 4})
+	if !s.Truncate(2) || !s.Skip(1) || !s.Unread(1) || s.Len() != 2 {
+		t.Errorf("Len() = %d after Truncate, Skip and Unread, want 2", s.Len())
+	}
+}
+
+func TestReadBytesCopy(t *testing.T) {
+	buf := []byte{1, 2, 3}
+	s := NewString(buf)
+	var alias, copied []byte
+	if !s.ReadBytes(&alias, 1) || !s.ReadBytesCopy(&copied, 2) {
+		t.Fatal("parsing failed")
+	}
+	buf[0], buf[1], buf[2] = 0, 0, 0
+	if alias[0] != 0 {
+		t.Error("ReadBytes() did not alias the buffer")
+	}
+	if want := []byte{2, 3}; !bytes.Equal(copied, want) {
+		t.Errorf("ReadBytesCopy() = %v after buffer reuse, want %v", copied, want)
+	}
+	if s.ReadBytesCopy(&copied, 1) {
+		t.Error("ReadBytesCopy() = true on empty input, want false")
+	}
+}
+
+func TestAddHex(t *testing.T) {
+	var b Builder
+	b.AddHex("0102fF")
+	b.AddHex("")
+	if err := builderBytesEq(&b, 1, 2, 0xff); err != nil {
+		t.Error(err)
+	}
+
+	for _, s := range []string{"123", "zz"} {
+		var b Builder
+		b.AddHex(s)
+		if _, err := b.Bytes(); err == nil || !strings.HasPrefix(err.Error(), "littlebyte: invalid hex string") {
+			t.Errorf("AddHex(%q): Bytes() error = %v, want invalid hex string error", s, err)
+		}
+	}
+}
+
+func TestReadHexString(t *testing.T) {
+	s := NewString([]byte("\x04c0ffEE!"))
+	var n uint8
+	var v []byte
+	if !s.ReadUint8(&n) || !s.ReadHexString(&v, int(n)*2-2) {
+		t.Fatal("parsing failed")
+	}
+	if want := []byte{0xc0, 0xff, 0xee}; !bytes.Equal(v, want) {
+		t.Errorf("ReadHexString() = %x, want %x", v, want)
+	}
+	if s.ReadHexString(&v, 2) {
+		t.Error("ReadHexString() = true on short input, want false")
+	}
+	if s.Len() != 1 {
+		t.Errorf("ReadHexString() advanced on failure")
+	}
+
+	s = NewString([]byte("0g"))
+	if s.ReadHexString(&v, 2) || s.Len() != 2 {
+		t.Error("ReadHexString() accepted invalid hex")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("recover() = nil, want error; ReadHexString() did not panic")
+		}
+	}()
+	s.ReadHexString(&v, 1)
+}
+
+func TestReadEachLengthPrefixed(t *testing.T) {
+	tests := []struct {
+		read func(*String, func(String) bool) bool
+		in   []byte
+	}{
+		{(*String).ReadEachUint8LengthPrefixed, []byte{2, 'a', 'b', 0, 1, 'c'}},
+		{(*String).ReadEachUint16LengthPrefixed, []byte{2, 0, 'a', 'b', 0, 0, 1, 0, 'c'}},
+		{(*String).ReadEachUint24LengthPrefixed, []byte{2, 0, 0, 'a', 'b', 0, 0, 0, 1, 0, 0, 'c'}},
+		{(*String).ReadEachUint32LengthPrefixed, []byte{2, 0, 0, 0, 'a', 'b', 0, 0, 0, 0, 1, 0, 0, 0, 'c'}},
+	}
+	for i, tt := range tests {
+		var got []string
+		collect := func(v String) bool {
+			got = append(got, string(v.Bytes()))
+			return true
+		}
+		s := NewString(tt.in)
+		if !tt.read(&s, collect) || !s.Empty() {
+			t.Errorf("#%d: ReadEach() = false or left input, want true", i)
+		}
+		if want := []string{"ab", "", "c"}; fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("#%d: ReadEach() elements = %q, want %q", i, got, want)
+		}
+
+		s = NewString(tt.in[:len(tt.in)-1])
+		if tt.read(&s, collect) {
+			t.Errorf("#%d: ReadEach() = true on malformed element, want false", i)
+		}
+		if s.Len() != len(tt.in)-1 {
+			t.Errorf("#%d: ReadEach() advanced on failure", i)
+		}
+	}
+
+	calls := 0
+	s := NewString([]byte{1, 'a', 1, 'b'})
+	if s.ReadEachUint8LengthPrefixed(func(v String) bool {
+		calls++
+		return false
+	}) {
+		t.Error("ReadEachUint8LengthPrefixed() = true when fn failed, want false")
+	}
+	if calls != 1 {
+		t.Errorf("fn called %d times, want 1", calls)
+	}
+}
+
+func TestAddEachLengthPrefixed(t *testing.T) {
+	input := []string{"hello", "", "world"}
+	var b Builder
+	b.AddUint16LengthPrefixed(func(c *Builder) {
+		c.AddEachUint8LengthPrefixed(len(input), func(d *Builder, i int) {
+			d.AddString(input[i])
+		})
+	})
+	if err := builderBytesEq(&b, 13, 0, 5, 'h', 'e', 'l', 'l', 'o', 0, 5, 'w', 'o', 'r', 'l', 'd'); err != nil {
+		t.Error(err)
+	}
+
+	b = Builder{}
+	b.AddEachUint16LengthPrefixed(2, func(c *Builder, i int) {
+		c.AddUint8(uint8(i))
+	})
+	b.AddEachUint24LengthPrefixed(1, func(c *Builder, i int) {})
+	b.AddEachUint32LengthPrefixed(0, func(c *Builder, i int) {
+		t.Error("fn called for empty list")
+	})
+	if err := builderBytesEq(&b, 1, 0, 0, 1, 0, 1, 0, 0, 0); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestBytesWithPendingChild(t *testing.T) {
+	var b Builder
+	b.AddUint8(1)
+	b.AddUint8LengthPrefixed(func(c *Builder) {
+		c.AddUint16LengthPrefixed(func(d *Builder) {
+			const want = "littlebyte: Bytes called with 2 pending children, the outermost at offset 1"
+			if v, err := b.Bytes(); err == nil || err.Error() != want || v != nil {
+				t.Errorf("Bytes() = %v, %v; want nil, %q", v, err, want)
+			}
+			func() {
+				defer func() {
+					if recover() == nil {
+						t.Errorf("recover() = nil, want error; BytesOrPanic() did not panic")
+					}
+				}()
+				b.BytesOrPanic()
+			}()
+		})
+	})
+	if err := builderBytesEq(&b, 1, 2, 0, 0); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestBool(t *testing.T) {
+	var b Builder
+	b.AddBool(true)
+	b.AddBool(false)
+	if err := builderBytesEq(&b, 1, 0); err != nil {
+		t.Error(err)
+	}
+
+	s := NewString([]byte{1, 0, 2})
+	var x, y, z bool
+	if !s.ReadBool(&x) || !s.ReadBool(&y) || !s.ReadBool(&z) {
+		t.Fatal("ReadBool() = false, want true")
+	}
+	if !x || y || !z {
+		t.Errorf("ReadBool() = %v, %v, %v; want true, false, true", x, y, z)
+	}
+	if s.ReadBool(&x) {
+		t.Error("ReadBool() = true on empty input, want false")
+	}
+
+	s = NewString([]byte{1, 0, 2})
+	if !s.ReadBoolStrict(&x) || !s.ReadBoolStrict(&y) || !x || y {
+		t.Errorf("ReadBoolStrict() = %v, %v; want true, false", x, y)
+	}
+	if s.ReadBoolStrict(&z) || s.Len() != 1 {
+		t.Error("ReadBoolStrict() accepted 2")
+	}
+}
+
+func TestReadUint16LengthPrefixedRaw(t *testing.T) {
+	s := NewString([]byte{2, 0, 7, 9, 1})
+	var raw []byte
+	var v String
+	if !s.ReadUint16LengthPrefixedRaw(&raw, &v) {
+		t.Fatal("ReadUint16LengthPrefixedRaw() = false, want true")
+	}
+	var x uint8
+	if !v.ReadUint8(&x) || x != 7 {
+		t.Errorf("ReadUint8() read %d, want 7", x)
+	}
+	if got, want := raw, []byte{7, 9}; !bytes.Equal(got, want) {
+		t.Errorf("raw = %v, want %v", got, want)
+	}
+	if s.Len() != 1 {
+		t.Errorf("s.Len() = %d, want 1", s.Len())
+	}
+
+	if s.ReadUint16LengthPrefixedRaw(&raw, &v) {
+		t.Error("ReadUint16LengthPrefixedRaw() = true for short input, want false")
+	}
+}
+
+func TestReadUint16LengthPrefixedExact(t *testing.T) {
+	var x uint8
+	readOne := func(s *String) bool {
+		return s.ReadUint8(&x)
+	}
+	s := NewString([]byte{1, 0, 7, 9})
+	if !s.ReadUint16LengthPrefixedExact(readOne) || x != 7 {
+		t.Errorf("ReadUint16LengthPrefixedExact() read %d, want 7", x)
+	}
+	if s.Len() != 1 {
+		t.Errorf("s.Len() = %d, want 1", s.Len())
+	}
+
+	s = NewString([]byte{2, 0, 7, 8})
+	if s.ReadUint16LengthPrefixedExact(readOne) {
+		t.Error("ReadUint16LengthPrefixedExact() = true with trailing bytes, want false")
+	}
+	if s.Len() != 4 {
+		t.Error("ReadUint16LengthPrefixedExact() advanced on failure")
+	}
+	s = NewString([]byte{0, 0})
+	if s.ReadUint16LengthPrefixedExact(readOne) {
+		t.Error("ReadUint16LengthPrefixedExact() = true when fn failed, want false")
+	}
+}
+
+func TestPadToCapacity(t *testing.T) {
+	b := NewFixedBuilder(make([]byte, 0, 6))
+	b.AddUint16(1)
+	b.PadToCapacity(0xff)
+	if err := builderBytesEq(b, 1, 0, 0xff, 0xff, 0xff, 0xff); err != nil {
+		t.Error(err)
+	}
+	b.PadToCapacity(0xff)
+	if _, err := b.Bytes(); err != nil {
+		t.Errorf("Bytes() error = %v after padding a full buffer, want nil", err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("recover() = nil, want error; PadToCapacity() did not panic")
+		}
+	}()
+	var g Builder
+	g.PadToCapacity(0)
+}
+
+func TestCapAvailable(t *testing.T) {
+	b := NewFixedBuilder(make([]byte, 0, 8))
+	b.AddUint16(1)
+	if b.Cap() != 8 || b.Available() != 6 {
+		t.Errorf("Cap(), Available() = %d, %d; want 8, 6", b.Cap(), b.Available())
+	}
+	b.AddUint8LengthPrefixed(func(c *Builder) {
+		c.AddUint8(1)
+		if c.Cap() != 5 || c.Available() != 4 {
+			t.Errorf("c.Cap(), c.Available() = %d, %d; want 5, 4", c.Cap(), c.Available())
+		}
+	})
+	if b.Available() != 4 {
+		t.Errorf("Available() = %d, want 4", b.Available())
+	}
+
+	var g Builder
+	g.AddUint32(1)
+	if g.Cap() != maxInt || g.Available() != maxInt-4 {
+		t.Errorf("Cap(), Available() = %d, %d for growable Builder; want %d, %d", g.Cap(), g.Available(), maxInt, maxInt-4)
+	}
+}
+
+func TestCopyTo(t *testing.T) {
+	s := NewString([]byte{1, 2, 3, 4, 5})
+	buf := make([]byte, 2)
+	var got []byte
+	for {
+		n := s.CopyTo(buf)
+		if n == 0 {
+			break
+		}
+		got = append(got, buf[:n]...)
+	}
+	if want := []byte{1, 2, 3, 4, 5}; !bytes.Equal(got, want) {
+		t.Errorf("CopyTo() copied %v, want %v", got, want)
+	}
+	if !s.Empty() {
+		t.Errorf("s.Len() = %d, want 0", s.Len())
+	}
+	if allocs := testing.AllocsPerRun(10, func() {
+		s := NewString([]byte{1, 2, 3})
+		s.CopyTo(buf)
+	}); allocs != 0 {
+		t.Errorf("CopyTo() allocated %v times, want 0", allocs)
+	}
+}
+
+func TestAddUint32LengthPrefixedSized(t *testing.T) {
+	var b Builder
+	b.AddUint32LengthPrefixedSized(3, func(c *Builder) {
+		c.AddUint8LengthPrefixed(func(d *Builder) {
+			d.AddUint16(1)
+		})
+	})
+	if err := builderBytesEq(&b, 3, 0, 0, 0, 2, 1, 0); err != nil {
+		t.Error(err)
+	}
+
+	for _, n := range []int{2, 4} {
+		var b Builder
+		b.AddUint32LengthPrefixedSized(3, func(c *Builder) {
+			c.AddZeros(n)
+		})
+		want := fmt.Sprintf("littlebyte: pending child length %d does not match declared size 3", n)
+		if _, err := b.Bytes(); err == nil || err.Error() != want {
+			t.Errorf("Bytes() error = %v, want %q", err, want)
+		}
+	}
+}
+
+func TestReadLengthPrefixedBE(t *testing.T) {
+	tests := []struct {
+		read func(*String, *String) bool
+		in   []byte
+	}{
+		{(*String).ReadUint16LengthPrefixedBE, []byte{0, 2, 1, 0, 9}},
+		{(*String).ReadUint24LengthPrefixedBE, []byte{0, 0, 2, 1, 0, 9}},
+		{(*String).ReadUint32LengthPrefixedBE, []byte{0, 0, 0, 2, 1, 0, 9}},
+	}
+	for i, tt := range tests {
+		s := NewString(tt.in)
+		var body String
+		var v uint16
+		if !tt.read(&s, &body) || !body.ReadUint16(&v) || !body.Empty() || v != 1 {
+			t.Errorf("#%d: body = %d, want little-endian 1", i, v)
+		}
+		if s.Len() != 1 {
+			t.Errorf("#%d: s.Len() = %d, want 1", i, s.Len())
+		}
+
+		s = NewString(tt.in[:len(tt.in)-2])
+		if tt.read(&s, &body) {
+			t.Errorf("#%d: read = true on short input, want false", i)
+		}
+	}
+}
+
+func TestRollback(t *testing.T) {
+	var b Builder
+	b.AddUint8(1)
+	mark := b.Mark()
+	b.AddUint16LengthPrefixed(func(c *Builder) {
+		c.AddUint32(2)
+	})
+	b.Rollback(mark)
+	b.AddUint8LengthPrefixed(func(c *Builder) {
+		c.AddUint8(3)
+	})
+	if err := builderBytesEq(&b, 1, 1, 3); err != nil {
+		t.Error(err)
+	}
+
+	// Rolling back to the current position does nothing.
+	b.Rollback(b.Mark())
+	if b.Len() != 3 {
+		t.Errorf("Len() = %d, want 3", b.Len())
+	}
+
+	for _, mark := range []int{-1, 4} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("recover() = nil, want error; Rollback(%d) did not panic", mark)
+				}
+			}()
+			b.Rollback(mark)
+		}()
+	}
+
+	b.AddUint8LengthPrefixed(func(c *Builder) {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("recover() = nil, want error; Rollback() did not panic")
+			}
+		}()
+		b.Rollback(0) // panics (attempted rollback while child is pending)
+	})
+}
+
+func TestReadCStringMax(t *testing.T) {
+	s := NewString([]byte("abc\x00de\x00fgh"))
+	var v string
+	if !s.ReadCStringMax(&v, 4) || v != "abc" {
+		t.Errorf("ReadCStringMax() read %q, want %q", v, "abc")
+	}
+	if s.ReadCStringMax(&v, 2) {
+		t.Error("ReadCStringMax() = true with NUL beyond max, want false")
+	}
+	if !s.ReadCStringMax(&v, 100) || v != "de" {
+		t.Errorf("ReadCStringMax() read %q, want %q", v, "de")
+	}
+	for _, max := range []int{-1, 0, 100} {
+		if s.ReadCStringMax(&v, max) {
+			t.Errorf("ReadCStringMax(%d) = true for unterminated string, want false", max)
+		}
+	}
+	if s.Len() != 3 {
+		t.Errorf("s.Len() = %d, want 3", s.Len())
+	}
+}
+
+func TestFillInto(t *testing.T) {
+	buf := make([]byte, 4, 8)
+	n, err := FillInto(buf, func(b *Builder) {
+		b.AddUint8LengthPrefixed(func(c *Builder) {
+			c.AddUint16(0x0201)
+		})
+	})
+	if err != nil || n != 3 {
+		t.Errorf("FillInto() = %d, %v; want 3, nil", n, err)
+	}
+	if got, want := buf[:n], []byte{2, 1, 2}; !bytes.Equal(got, want) {
+		t.Errorf("buf = %v, want %v", got, want)
+	}
+
+	// The capacity of buf limits the output, not its length.
+	n, err = FillInto(buf[:0], func(b *Builder) {
+		b.AddZeros(9)
+	})
+	if err == nil || n != 0 {
+		t.Errorf("FillInto() = %d, %v; want 0, error", n, err)
+	}
+
+	failed := errors.New("failed")
+	_, err = FillInto(buf, func(b *Builder) {
+		panic(BuildError{Err: failed})
+	})
+	if err != failed {
+		t.Errorf("FillInto() error = %v, want %v", err, failed)
+	}
+}
+
+func TestIsFixed(t *testing.T) {
+	var b Builder
+	if b.IsFixed() {
+		t.Error("IsFixed() = true for a zero Builder, want false")
+	}
+	if NewBuilder(make([]byte, 0, 4)).IsFixed() {
+		t.Error("IsFixed() = true for NewBuilder, want false")
+	}
+	fixed := NewFixedBuilder(make([]byte, 0, 4))
+	if !fixed.IsFixed() {
+		t.Error("IsFixed() = false for NewFixedBuilder, want true")
+	}
+	fixed.AddUint8LengthPrefixed(func(c *Builder) {
+		if !c.IsFixed() {
+			t.Error("IsFixed() = false for a child of a fixed Builder, want true")
+		}
+	})
+}
+
+func TestUnwriteWholeChild(t *testing.T) {
+	var b Builder
+	b.AddUint8(1)
+	b.AddUint8LengthPrefixed(func(c *Builder) {
+		c.AddUint8(2)
+	})
+	b.AddUint16LengthPrefixed(func(c *Builder) {
+		c.AddUint8(3)
+	})
+	b.Unwrite(3)
+	if err := builderBytesEq(&b, 1, 1, 2); err != nil {
+		t.Error(err)
+	}
+	b.Unwrite(2)
+	b.AddUint8(4)
+	if err := builderBytesEq(&b, 1, 4); err != nil {
+		t.Error(err)
+	}
+
+	b.AddUint8LengthPrefixed(func(c *Builder) {
+		c.AddUint16(5)
+	})
+	defer func() {
+		if recover() == nil {
+			t.Errorf("recover() = nil, want error; Unwrite() did not panic")
+		}
+	}()
+	b.Unwrite(2) // panics (cuts into a completed child)
+}
+
+func TestRollbackThenUnwrite(t *testing.T) {
+	var b Builder
+	b.AddBytes([]byte{1, 2, 3, 4, 5})
+	mark := b.Mark()
+	b.AddUint8LengthPrefixed(func(c *Builder) {
+		c.AddUint8(6)
+	})
+	b.Rollback(mark)
+	// No completed child precedes the mark, so plain bytes can be unwritten.
+	b.Unwrite(2)
+	if err := builderBytesEq(&b, 1, 2, 3); err != nil {
+		t.Error(err)
+	}
+
+	// An earlier child is still protected after rolling back a later one.
+	b.AddUint8LengthPrefixed(func(c *Builder) {
+		c.AddUint8(7)
+	})
+	b.AddUint8(8)
+	mark = b.Mark()
+	b.AddUint8LengthPrefixed(func(c *Builder) {
+		c.AddUint8(9)
+	})
+	b.Rollback(mark)
+	b.Unwrite(1)
+	defer func() {
+		if recover() == nil {
+			t.Errorf("recover() = nil, want error; Unwrite() did not panic")
+		}
+	}()
+	b.Unwrite(1)
+}
+
+func TestInsertAt(t *testing.T) {
+	var b Builder
+	b.AddUint8LengthPrefixed(func(c *Builder) {
+		c.AddUint8(1)
+		c.AddUint8LengthPrefixed(func(c *Builder) {
+			c.AddUint8(3)
+		})
+		// Inserting into the pending parent's contents updates its length.
+		c.InsertAt(1, []byte{2})
+	})
+	b.InsertAt(0, []byte{0xff})
+	if err := builderBytesEq(&b, 0xff, 4, 1, 2, 1, 3); err != nil {
+		t.Error(err)
+	}
+
+	// The completed child moved, so unwriting into it still panics.
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("recover() = nil, want error; Unwrite() did not panic")
+			}
+		}()
+		b.Unwrite(1)
+	}()
+
+	b.InsertAt(b.Len(), []byte{5})
+	if err := builderBytesEq(&b, 0xff, 4, 1, 2, 1, 3, 5); err != nil {
+		t.Error(err)
+	}
+
+	for _, offset := range []int{-1, 8} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("recover() = nil, want error; InsertAt(%d) did not panic", offset)
+				}
+			}()
+			b.InsertAt(offset, []byte{0})
+		}()
+	}
+
+	b.AddUint8LengthPrefixed(func(c *Builder) {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("recover() = nil, want error; InsertAt() did not panic")
+			}
+		}()
+		b.InsertAt(0, []byte{0}) // panics (attempted insert while child is pending)
+	})
+
+	fixed := NewFixedBuilder(make([]byte, 0, 2))
+	fixed.AddUint16(0)
+	fixed.InsertAt(0, []byte{1})
+	if _, err := fixed.Bytes(); err == nil {
+		t.Error("Bytes() error = nil after overflowing a fixed-size buffer, want error")
+	}
+}
+
+func TestAddBytesNoCopy(t *testing.T) {
+	v := []byte{1, 2, 3, 0}
+	var b Builder
+	b.AddBytesNoCopy(v[:3])
+	if out := b.BytesOrPanic(); &out[0] != &v[0] {
+		t.Error("AddBytesNoCopy() copied into an empty Builder, want v to be used")
+	}
+	b.AddUint8(4)
+	if err := builderBytesEq(&b, 1, 2, 3, 4); err != nil {
+		t.Error(err)
+	}
+	if v[3] != 0 {
+		t.Errorf("v[3] = %d after a later append, want 0", v[3])
+	}
+
+	// Released Builders do not reuse a buffer taken from the caller.
+	w := []byte{1, 2, 3}
+	p := Acquire()
+	p.AddBytesNoCopy(w)
+	Release(p)
+	if p.result != nil {
+		t.Error("Release() kept a buffer taken by AddBytesNoCopy")
+	}
+
+	// A streaming Builder copies, since Flush reuses its buffer.
+	var buf bytes.Buffer
+	sb := NewStreamingBuilder(&buf)
+	sb.AddBytesNoCopy(w)
+	if err := sb.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	sb.AddUint16(0x0909)
+	if !bytes.Equal(w, []byte{1, 2, 3}) {
+		t.Errorf("w = %v after Flush and append, want [1 2 3]", w)
+	}
+
+	// A Builder with its own buffer copies.
+	c := NewBuilder(make([]byte, 0, 8))
+	c.AddBytesNoCopy(v)
+	if out := c.BytesOrPanic(); &out[0] == &v[0] {
+		t.Error("AddBytesNoCopy() used v in a preallocated Builder, want a copy")
+	}
+	c.AddBytesNoCopy(v[:1])
+	if err := builderBytesEq(c, 1, 2, 3, 0, 1); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestAddBuilder(t *testing.T) {
+	var part Builder
+	part.AddUint8LengthPrefixed(func(c *Builder) {
+		c.AddUint8(2)
+	})
+	var b Builder
+	b.AddUint8(0)
+	b.AddBuilder(&part)
+	b.AddBuilder(new(Builder))
+	if err := builderBytesEq(&b, 0, 1, 2); err != nil {
+		t.Error(err)
+	}
+
+	failed := errors.New("part failed")
+	part.SetError(failed)
+	b.AddBuilder(&part)
+	if _, err := b.Bytes(); err != failed {
+		t.Errorf("Bytes() error = %v, want %v", err, failed)
+	}
+
+	b = Builder{}
+	var pending Builder
+	pending.AddUint8LengthPrefixed(func(c *Builder) {
+		b.AddBuilder(&pending)
+	})
+	if _, err := b.Bytes(); err == nil {
+		t.Error("Bytes() error = nil after adding a Builder with a pending child, want error")
+	}
+}
+
+func TestFixedString(t *testing.T) {
+	var b Builder
+	b.AddFixedString("ab", 4, ' ')
+	b.AddFixedString("cdef", 4, 0)
+	b.AddFixedString("", 2, 0)
+	if err := builderBytesEq(&b, 'a', 'b', ' ', ' ', 'c', 'd', 'e', 'f', 0, 0); err != nil {
+		t.Error(err)
+	}
+
+	s := NewString(b.BytesOrPanic())
+	var x, y, z string
+	if !s.ReadFixedString(&x, 4, ' ') || !s.ReadFixedString(&y, 4, 0) || !s.ReadFixedString(&z, 2, 0) {
+		t.Fatal("ReadFixedString() = false, want true")
+	}
+	if x != "ab" || y != "cdef" || z != "" {
+		t.Errorf("ReadFixedString() = %q, %q, %q; want %q, %q, %q", x, y, z, "ab", "cdef", "")
+	}
+	if s.ReadFixedString(&x, 1, 0) {
+		t.Error("ReadFixedString() = true on empty input, want false")
+	}
+
+	b = Builder{}
+	b.AddFixedString("toolong", 4, ' ')
+	if _, err := b.Bytes(); err == nil {
+		t.Error("Bytes() error = nil after AddFixedString of over-long string, want error")
+	}
+}
@@ -0,0 +1,178 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package littlebyte
+
+import "hash/crc32"
+
+// written returns the bytes written to the Builder starting at the given
+// position, as returned by Len. It panics if a child is pending or if from is
+// out of range.
+func (b *Builder) written(from int) []byte {
+	if b.child != nil {
+		panic("littlebyte: attempted checksum while child is pending")
+	}
+	start := b.offset + b.pendingLenLen
+	if b.stream != nil && b.stream.root == b {
+		from -= b.stream.flushed
+	}
+	if from < 0 || from > len(b.result)-start {
+		panic("littlebyte: checksum offset out of range")
+	}
+	return b.result[start+from:]
+}
+
+// AddCRC32 computes the CRC-32 checksum, using the given table, of the bytes
+// written since from and appends it as a little-endian, 32-bit value. The
+// start position from is typically obtained by calling Len before writing the
+// checksummed bytes.
+func (b *Builder) AddCRC32(tab *crc32.Table, from int) {
+	if b.err != nil {
+		return
+	}
+	b.AddUint32(crc32.Checksum(b.written(from), tab))
+}
+
+// A CRC16 describes a 16-bit cyclic redundancy check.
+type CRC16 struct {
+	// Poly is the generator polynomial. If Reflected is true, it is given in
+	// reversed bit order, as is conventional for reflected CRCs.
+	Poly uint16
+	// Init is the initial value of the CRC register.
+	Init uint16
+	// Reflected reports whether bits are processed least significant first.
+	Reflected bool
+}
+
+var (
+	// CRC16CCITT is the CRC-16/CCITT-FALSE checksum, with polynomial 0x1021.
+	CRC16CCITT = CRC16{Poly: 0x1021, Init: 0xffff}
+	// CRC16Modbus is the checksum used by Modbus RTU, with the reflected
+	// polynomial 0xa001.
+	CRC16Modbus = CRC16{Poly: 0xa001, Init: 0xffff, Reflected: true}
+)
+
+// Checksum returns the CRC of data.
+func (c CRC16) Checksum(data []byte) uint16 {
+	return crc16Update(c, c.Init, data)
+}
+
+// crc16Update returns the result of adding the bytes in data to crc, a
+// running CRC with parameters c.
+func crc16Update(c CRC16, crc uint16, data []byte) uint16 {
+	for _, v := range data {
+		if c.Reflected {
+			crc ^= uint16(v)
+			for i := 0; i < 8; i++ {
+				if crc&1 != 0 {
+					crc = crc>>1 ^ c.Poly
+				} else {
+					crc >>= 1
+				}
+			}
+		} else {
+			crc ^= uint16(v) << 8
+			for i := 0; i < 8; i++ {
+				if crc&0x8000 != 0 {
+					crc = crc<<1 ^ c.Poly
+				} else {
+					crc <<= 1
+				}
+			}
+		}
+	}
+	return crc
+}
+
+// AddCRC16 computes the given 16-bit CRC of the bytes written since from and
+// appends it as a little-endian, 16-bit value, as for AddCRC32.
+func (b *Builder) AddCRC16(c CRC16, from int) {
+	if b.err != nil {
+		return
+	}
+	b.AddUint16(c.Checksum(b.written(from)))
+}
+
+// AddChecksumPrefixed adds a byte sequence preceded by a width-byte checksum of
+// its content. The content is written by f, in the same way as for
+// AddUint8LengthPrefixed etc. Once f returns, sum is called with the content
+// and its result is written into the reserved prefix as a little-endian value.
+// If the result does not fit in width bytes, an error is set on the Builder.
+// width must be between 1 and 8.
+func (b *Builder) AddChecksumPrefixed(width int, sum func([]byte) uint64, f BuilderContinuation) {
+	if width < 1 || width > 8 {
+		panic("littlebyte: invalid checksum width")
+	}
+	b.addPrefixed(width, func(v []byte) (uint64, error) {
+		return sum(v), nil
+	}, f)
+}
+
+// A ChecksumRegion tracks the bytes read from a String so that a trailing
+// checksum covering them can be verified. Bytes can be excluded from the
+// checksum, for fields that are modified in transit, so the covered region
+// need not be contiguous.
+type ChecksumRegion struct {
+	s        *String
+	mark     String
+	included [][]byte
+}
+
+// BeginChecksumRegion starts a checksum region at the current position of s.
+// Bytes subsequently read from s are covered by the checksum, except for those
+// skipped by calling Exclude.
+func (s *String) BeginChecksumRegion() *ChecksumRegion {
+	return &ChecksumRegion{s: s, mark: *s}
+}
+
+// flush adds the bytes read since the last mark to the covered region.
+func (cr *ChecksumRegion) flush() {
+	n := cr.s.ConsumedSince(cr.mark)
+	cr.included = append(cr.included, cr.mark.data[:n])
+	cr.mark = *cr.s
+}
+
+// Exclude advances the underlying String by n bytes, excluding them from the
+// checksum. It reports whether it was successful.
+func (cr *ChecksumRegion) Exclude(n int) bool {
+	cr.flush()
+	if cr.s.read(n) == nil {
+		return cr.s.fail("Exclude")
+	}
+	cr.mark = *cr.s
+	return true
+}
+
+// VerifyCRC32 reads a little-endian, 32-bit CRC-32 checksum from the
+// underlying String and advances over it. It reports whether the read was
+// successful and the checksum, computed using the given table, matches the
+// bytes covered by the region.
+func (cr *ChecksumRegion) VerifyCRC32(tab *crc32.Table) bool {
+	cr.flush()
+	var crc uint32
+	for _, v := range cr.included {
+		crc = crc32.Update(crc, tab, v)
+	}
+	var want uint32
+	if !cr.s.readUnsigned(&want, 4) || crc != want {
+		return cr.s.fail("VerifyCRC32")
+	}
+	return true
+}
+
+// VerifyCRC16 reads a little-endian, 16-bit checksum from the underlying
+// String and advances over it. It reports whether the read was successful and
+// the checksum matches the given CRC of the bytes covered by the region.
+func (cr *ChecksumRegion) VerifyCRC16(c CRC16) bool {
+	cr.flush()
+	crc := c.Init
+	for _, v := range cr.included {
+		crc = crc16Update(c, crc, v)
+	}
+	var want uint32
+	if !cr.s.readUnsigned(&want, 2) || uint32(crc) != want {
+		return cr.s.fail("VerifyCRC16")
+	}
+	return true
+}
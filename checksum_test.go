@@ -0,0 +1,184 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package littlebyte
+
+import (
+	"hash/crc32"
+	"testing"
+)
+
+func TestAddCRC32(t *testing.T) {
+	var b Builder
+	b.AddUint8(0xff)
+	start := b.Len()
+	b.AddBytes([]byte("123456789"))
+	b.AddCRC32(crc32.IEEETable, start)
+
+	s := NewString(b.BytesOrPanic())
+	var payload []byte
+	var sum uint32
+	if !s.Skip(1) || !s.ReadBytes(&payload, 9) || !s.ReadUint32(&sum) || !s.Empty() {
+		t.Fatal("parsing failed")
+	}
+	if sum != 0xcbf43926 {
+		t.Errorf("sum = %#x, want 0xcbf43926", sum)
+	}
+
+	b = Builder{}
+	b.AddUint16LengthPrefixed(func(c *Builder) {
+		c.AddBytes([]byte("123456789"))
+		c.AddCRC32(crc32.IEEETable, 0)
+	})
+	if err := builderBytesEq(&b, 13, 0, '1', '2', '3', '4', '5', '6', '7', '8', '9', 0x26, 0x39, 0xf4, 0xcb); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestAddCRC32Invalid(t *testing.T) {
+	for _, from := range []int{-1, 4} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("recover() = nil, want error; AddCRC32(%d) did not panic", from)
+				}
+			}()
+			var b Builder
+			b.AddBytes([]byte{1, 2, 3})
+			b.AddCRC32(crc32.IEEETable, from) // panics
+		}()
+	}
+
+	var b Builder
+	b.AddUint8LengthPrefixed(func(c *Builder) {
+		c.AddUint8(1)
+		defer func() {
+			if recover() == nil {
+				t.Errorf("recover() = nil, want error; AddCRC32() did not panic")
+			}
+		}()
+		b.AddCRC32(crc32.IEEETable, 0) // panics (child is pending)
+	})
+}
+
+func TestAddChecksumPrefixed(t *testing.T) {
+	sum := func(v []byte) uint64 {
+		return uint64(crc32.ChecksumIEEE(v))
+	}
+
+	var b Builder
+	b.AddUint8(0xff)
+	b.AddChecksumPrefixed(4, sum, func(c *Builder) {
+		c.AddBytes([]byte("123456"))
+		c.AddUint8LengthPrefixed(func(d *Builder) {
+			d.AddBytes([]byte("89"))
+		})
+	})
+	if err := builderBytesEq(&b, 0xff, 0x5d, 0x1e, 0x54, 0xe9, '1', '2', '3', '4', '5', '6', 2, '8', '9'); err != nil {
+		t.Error(err)
+	}
+
+	b = Builder{}
+	b.AddChecksumPrefixed(2, sum, func(c *Builder) {
+		c.AddBytes([]byte("123456789"))
+	})
+	if _, err := b.Bytes(); err == nil {
+		t.Error("Bytes() error = nil, want error for oversized checksum")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("recover() = nil, want error; AddChecksumPrefixed() did not panic")
+		}
+	}()
+	b = Builder{}
+	b.AddChecksumPrefixed(9, sum, func(c *Builder) {}) // panics
+}
+
+func TestChecksumRegion(t *testing.T) {
+	frame := func(hops uint8, corrupt bool) String {
+		var b Builder
+		b.AddBytes([]byte("1234"))
+		b.AddUint8(hops)
+		b.AddBytes([]byte("56789"))
+		crc := crc32.ChecksumIEEE([]byte("123456789"))
+		if corrupt {
+			crc ^= 1
+		}
+		b.AddUint32(crc)
+		return NewString(b.BytesOrPanic())
+	}
+
+	for _, test := range []struct {
+		hops    uint8
+		corrupt bool
+		want    bool
+	}{
+		{0, false, true},
+		{7, false, true},
+		{7, true, false},
+	} {
+		s := frame(test.hops, test.corrupt)
+		cr := s.BeginChecksumRegion()
+		var header, payload []byte
+		if !s.ReadBytes(&header, 4) || !cr.Exclude(1) || !s.ReadBytes(&payload, 5) {
+			t.Fatal("parsing failed")
+		}
+		if got := cr.VerifyCRC32(crc32.IEEETable); got != test.want {
+			t.Errorf("hops=%d corrupt=%v: VerifyCRC32() = %v, want %v", test.hops, test.corrupt, got, test.want)
+		}
+		if !s.Empty() {
+			t.Errorf("s.Len() = %d, want 0", s.Len())
+		}
+	}
+
+	s := NewString([]byte{1, 2})
+	cr := s.BeginChecksumRegion()
+	if cr.Exclude(3) {
+		t.Error("Exclude() = true past the end, want false")
+	}
+	if cr.VerifyCRC32(crc32.IEEETable) {
+		t.Error("VerifyCRC32() = true on truncated input, want false")
+	}
+}
+
+func TestCRC16(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		c    CRC16
+		want uint16
+	}{
+		{"CCITT", CRC16CCITT, 0x29b1},
+		{"Modbus", CRC16Modbus, 0x4b37},
+	} {
+		if got := test.c.Checksum([]byte("123456789")); got != test.want {
+			t.Errorf("%s: Checksum() = %#04x, want %#04x", test.name, got, test.want)
+		}
+
+		var b Builder
+		b.AddUint8(0xff)
+		start := b.Len()
+		b.AddBytes([]byte("123456789"))
+		b.AddCRC16(test.c, start)
+		out := b.BytesOrPanic()
+		if got := uint16(out[10]) | uint16(out[11])<<8; got != test.want {
+			t.Errorf("%s: AddCRC16() wrote %#04x, want %#04x", test.name, got, test.want)
+		}
+
+		s := NewString(out[1:])
+		cr := s.BeginChecksumRegion()
+		s.Skip(9)
+		if !cr.VerifyCRC16(test.c) {
+			t.Errorf("%s: VerifyCRC16() = false, want true", test.name)
+		}
+
+		out[5] ^= 1
+		s = NewString(out[1:])
+		cr = s.BeginChecksumRegion()
+		s.Skip(9)
+		if cr.VerifyCRC16(test.c) {
+			t.Errorf("%s: VerifyCRC16() = true for corrupted data, want false", test.name)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package littlebyte
+
+import "bytes"
+
+// AddCOBS appends payload encoded with Consistent Overhead Byte Stuffing,
+// followed by a zero byte as a delimiter. The encoding contains no zero
+// bytes, so the delimiter marks the end of the packet unambiguously. It adds
+// one byte of overhead for each 254 bytes of payload, plus the delimiter.
+func (b *Builder) AddCOBS(payload []byte) {
+	buf := make([]byte, 1, len(payload)+len(payload)/254+2)
+	code := 0 // index of the code byte of the current block
+	for i, v := range payload {
+		if v != 0 {
+			buf = append(buf, v)
+			if len(buf)-code < 0xff || i == len(payload)-1 {
+				continue
+			}
+			// A full block of 254 non-zero bytes has no implied zero.
+		}
+		buf[code] = uint8(len(buf) - code)
+		code = len(buf)
+		buf = append(buf, 0)
+	}
+	buf[code] = uint8(len(buf) - code)
+	buf = append(buf, 0)
+	b.add(buf...)
+}
+
+// ReadCOBS decodes a packet encoded with Consistent Overhead Byte Stuffing,
+// as written by AddCOBS, into out and advances over it and the zero byte that
+// delimits it. It reports whether the read was successful, which requires
+// that a delimiter is found and that each code byte in the packet points
+// within it. If not, s is unchanged. out is a new slice, since decoding
+// changes the bytes.
+func (s *String) ReadCOBS(out *[]byte) bool {
+	n := bytes.IndexByte(s.data, 0)
+	if n <= 0 {
+		return s.fail("ReadCOBS")
+	}
+	packet := s.data[:n]
+	v := make([]byte, 0, n)
+	for i := 0; i < n; {
+		code := int(packet[i])
+		if i+code > n {
+			return s.fail("ReadCOBS")
+		}
+		v = append(v, packet[i+1:i+code]...)
+		i += code
+		if code < 0xff && i < n {
+			v = append(v, 0)
+		}
+	}
+	s.read(n + 1)
+	*out = v
+	return true
+}
@@ -0,0 +1,76 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package littlebyte
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestCOBS(t *testing.T) {
+	seq := func(from, to int) []byte {
+		var v []byte
+		for i := from; i <= to; i++ {
+			v = append(v, uint8(i))
+		}
+		return v
+	}
+	cat := func(vs ...[]byte) []byte {
+		return bytes.Join(vs, nil)
+	}
+	for _, test := range []struct {
+		in, want []byte
+	}{
+		{[]byte{}, []byte{1, 0}},
+		{[]byte{0}, []byte{1, 1, 0}},
+		{[]byte{0, 0}, []byte{1, 1, 1, 0}},
+		{[]byte{0, 0x11, 0}, []byte{1, 2, 0x11, 1, 0}},
+		{[]byte{0x11, 0x22, 0, 0x33}, []byte{3, 0x11, 0x22, 2, 0x33, 0}},
+		{[]byte{0x11, 0, 0, 0}, []byte{2, 0x11, 1, 1, 1, 0}},
+		{seq(1, 254), cat([]byte{0xff}, seq(1, 254), []byte{0})},
+		{seq(0, 254), cat([]byte{1, 0xff}, seq(1, 254), []byte{0})},
+		{seq(1, 255), cat([]byte{0xff}, seq(1, 254), []byte{2, 0xff, 0})},
+		{cat(seq(2, 255), []byte{0}), cat([]byte{0xff}, seq(2, 255), []byte{1, 1, 0})},
+		{cat(seq(3, 255), []byte{0, 1}), cat([]byte{0xfe}, seq(3, 255), []byte{2, 1, 0})},
+	} {
+		var b Builder
+		b.AddCOBS(test.in)
+		got := b.BytesOrPanic()
+		if !bytes.Equal(got, test.want) {
+			t.Errorf("AddCOBS(%x) = %x, want %x", test.in, got, test.want)
+			continue
+		}
+
+		s := NewString(append(got, 0xaa))
+		var v []byte
+		if !s.ReadCOBS(&v) {
+			t.Errorf("ReadCOBS(%x) = false, want true", got)
+			continue
+		}
+		if !bytes.Equal(v, test.in) {
+			t.Errorf("ReadCOBS(%x) read %x, want %x", got, v, test.in)
+		}
+		if s.Len() != 1 {
+			t.Errorf("s.Len() = %d, want 1", s.Len())
+		}
+	}
+
+	for _, in := range [][]byte{
+		{},
+		{0},          // empty packet
+		{2, 0x11},    // no delimiter
+		{3, 0x11, 0}, // code points past the delimiter
+		{1, 4, 1, 0}, // chained code points past the delimiter
+	} {
+		s := NewString(in)
+		var v []byte
+		if s.ReadCOBS(&v) {
+			t.Errorf("ReadCOBS(%x) = true, want false", in)
+		}
+		if s.Len() != len(in) {
+			t.Errorf("ReadCOBS(%x) advanced on failure", in)
+		}
+	}
+}
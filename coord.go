@@ -0,0 +1,44 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package littlebyte
+
+import (
+	"fmt"
+	"math"
+)
+
+// coordScale is the scale factor of fixed-point coordinates: they are stored
+// as integer multiples of 1e-7 degrees.
+const coordScale = 1e7
+
+// AddCoordinate appends a latitude and longitude, in degrees, as a pair of
+// little-endian, 32-bit signed integers scaled by 1e7 and rounded to the
+// nearest integer. If the latitude is outside [-90, 90] or the longitude is
+// outside [-180, 180], an error is set on the Builder.
+func (b *Builder) AddCoordinate(lat, lon float64) {
+	if b.err != nil {
+		return
+	}
+	if !(lat >= -90 && lat <= 90) || !(lon >= -180 && lon <= 180) {
+		b.err = fmt.Errorf("littlebyte: coordinate (%v, %v) out of range", lat, lon)
+		return
+	}
+	b.AddUint32(uint32(int32(math.Round(lat * coordScale))))
+	b.AddUint32(uint32(int32(math.Round(lon * coordScale))))
+}
+
+// ReadCoordinate decodes a latitude and longitude, stored as a pair of
+// little-endian, 32-bit signed integers scaled by 1e7, into lat and lon and
+// advances over them. It reports whether the read was successful.
+func (s *String) ReadCoordinate(lat, lon *float64) bool {
+	var x, y uint32
+	v := NewString(s.read(8))
+	if v.Empty() || !v.ReadUint32(&x) || !v.ReadUint32(&y) {
+		return s.fail("ReadCoordinate")
+	}
+	*lat = float64(int32(x)) / coordScale
+	*lon = float64(int32(y)) / coordScale
+	return true
+}
@@ -0,0 +1,51 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package littlebyte
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCoordinate(t *testing.T) {
+	var b Builder
+	b.AddCoordinate(37.7749, -122.4194)
+	if err := builderBytesEq(&b, 0x08, 0xfe, 0x83, 0x16, 0x30, 0x48, 0x08, 0xb7); err != nil {
+		t.Error(err)
+	}
+
+	s := NewString(b.BytesOrPanic())
+	var lat, lon float64
+	if !s.ReadCoordinate(&lat, &lon) {
+		t.Fatal("ReadCoordinate() = false, want true")
+	}
+	if math.Abs(lat-37.7749) > 1e-7 || math.Abs(lon+122.4194) > 1e-7 {
+		t.Errorf("lat, lon = %v, %v; want 37.7749, -122.4194", lat, lon)
+	}
+	if s.Len() != 0 {
+		t.Errorf("s.Len() = %d, want 0", s.Len())
+	}
+
+	s = NewString([]byte{1, 2, 3, 4, 5, 6, 7})
+	if s.ReadCoordinate(&lat, &lon) {
+		t.Error("ReadCoordinate() = true, want false")
+	}
+}
+
+func TestCoordinateOutOfRange(t *testing.T) {
+	for _, c := range [][2]float64{
+		{91, 0},
+		{-90.5, 0},
+		{0, 180.1},
+		{0, -181},
+		{math.NaN(), 0},
+	} {
+		var b Builder
+		b.AddCoordinate(c[0], c[1])
+		if _, err := b.Bytes(); err == nil {
+			t.Errorf("AddCoordinate(%v, %v): Bytes() error = nil, want error", c[0], c[1])
+		}
+	}
+}
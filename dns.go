@@ -0,0 +1,61 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package littlebyte
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AddDNSName appends a domain name, such as "www.example.com.", in DNS wire
+// format: a sequence of labels, each prefixed by its 8-bit length, terminated
+// by a zero byte. The trailing dot is optional, and "." or "" denotes the
+// root. If the name has an empty label, a label longer than 63 bytes, or an
+// encoded length over 255 bytes, an error is set on the Builder.
+//
+// If compressionTable is not nil, the name is compressed: the longest suffix
+// of it found in the table is replaced by a big-endian, 14-bit pointer to the
+// offset recorded there, and the offsets of the suffixes written are added to
+// the table. Suffixes are matched exactly, so names should be given in a
+// consistent case. Offsets are as reported by Len, so the Builder must be the
+// one that holds the whole DNS message, not a child.
+func (b *Builder) AddDNSName(name string, compressionTable map[string]int) {
+	if b.err != nil {
+		return
+	}
+	name = strings.TrimSuffix(name, ".")
+	var labels []string
+	if name != "" {
+		labels = strings.Split(name, ".")
+	}
+	n := 1
+	for _, label := range labels {
+		if len(label) == 0 || len(label) > 63 {
+			b.err = fmt.Errorf("littlebyte: invalid label length %d in DNS name %q", len(label), name)
+			return
+		}
+		n += 1 + len(label)
+	}
+	if n > 255 {
+		b.err = fmt.Errorf("littlebyte: DNS name %q exceeds 255 bytes", name)
+		return
+	}
+
+	for i, label := range labels {
+		if compressionTable != nil {
+			suffix := strings.Join(labels[i:], ".")
+			if off, ok := compressionTable[suffix]; ok {
+				b.add(0xc0|byte(off>>8), byte(off))
+				return
+			}
+			if off := b.Len(); off < 0x4000 {
+				compressionTable[suffix] = off
+			}
+		}
+		b.AddUint8(uint8(len(label)))
+		b.AddString(label)
+	}
+	b.AddUint8(0)
+}
@@ -0,0 +1,63 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package littlebyte
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAddDNSName(t *testing.T) {
+	var b Builder
+	b.AddDNSName("www.example.com.", nil)
+	b.AddDNSName(".", nil)
+	want := []byte("\x03www\x07example\x03com\x00\x00")
+	if err := builderBytesEq(&b, want...); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestAddDNSNameCompression(t *testing.T) {
+	table := make(map[string]int)
+	var b Builder
+	b.AddUint16(0)
+	b.AddDNSName("www.example.com", table)
+	b.AddDNSName("mail.example.com", table)
+	b.AddDNSName("www.example.com.", table)
+	b.AddDNSName("org", table)
+	want := []byte("\x00\x00" +
+		"\x03www\x07example\x03com\x00" +
+		"\x04mail\xc0\x06" +
+		"\xc0\x02" +
+		"\x03org\x00")
+	if err := builderBytesEq(&b, want...); err != nil {
+		t.Error(err)
+	}
+	if off, ok := table["mail.example.com"]; !ok || off != 19 {
+		t.Errorf("table[%q] = %d, %v; want 19, true", "mail.example.com", off, ok)
+	}
+}
+
+func TestAddDNSNameInvalid(t *testing.T) {
+	long := strings.Repeat("a", 63)
+	for _, name := range []string{
+		"www..com",
+		".com",
+		long + "a.com",
+		strings.Repeat(long+".", 4),
+	} {
+		var b Builder
+		b.AddDNSName(name, nil)
+		if _, err := b.Bytes(); err == nil {
+			t.Errorf("AddDNSName(%q): Bytes() error = nil, want error", name)
+		}
+	}
+
+	var b Builder
+	b.AddDNSName(strings.Repeat(long+".", 3)+strings.Repeat("a", 61), nil)
+	if got, err := b.Bytes(); err != nil || len(got) != 255 {
+		t.Errorf("AddDNSName() of 255-byte name = %d bytes, %v; want 255, nil", len(got), err)
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package littlebyte
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+// VIntUnknown is the value of an EBML variable-length integer whose data bits
+// are all ones, which EBML reserves to mean an unknown size.
+const VIntUnknown = ^uint64(0)
+
+// maxVInt is the largest value that can be encoded as an EBML variable-length
+// integer in the maximum width of 8 bytes.
+const maxVInt = 1<<56 - 2
+
+// AddVInt appends v as an EBML variable-length integer, as used by Matroska,
+// in the shortest width that can hold it. The number of leading zero bits in
+// the first byte, plus one, gives the width in bytes, and the remaining bits
+// hold v in big-endian order. Since the all-ones value of each width is
+// reserved, VIntUnknown is encoded as the single byte 0xff. If v is neither
+// VIntUnknown nor at most 2^56-2, an error is set on the Builder.
+func (b *Builder) AddVInt(v uint64) {
+	if b.err != nil {
+		return
+	}
+	if v == VIntUnknown {
+		b.AddUint8(0xff)
+		return
+	}
+	if v > maxVInt {
+		b.err = fmt.Errorf("littlebyte: value %d too large for EBML variable-length integer", v)
+		return
+	}
+	// The all-ones value of each width is reserved, so v+1 must fit in the
+	// data bits.
+	n := (bits.Len64(v+1) + 6) / 7
+	v |= 1 << uint(7*n)
+	var buf [8]byte
+	for i := n - 1; i >= 0; i-- {
+		buf[i] = byte(v)
+		v >>= 8
+	}
+	b.add(buf[:n]...)
+}
+
+// ReadVInt decodes an EBML variable-length integer, as written by AddVInt,
+// into out and advances over it. An integer of any width whose data bits are
+// all ones is decoded as VIntUnknown. It reports whether the read was
+// successful. The read fails if the first byte is zero, which would indicate a
+// width of more than 8 bytes.
+func (s *String) ReadVInt(out *uint64) bool {
+	if len(s.data) == 0 || s.data[0] == 0 {
+		return s.fail("ReadVInt")
+	}
+	n := bits.LeadingZeros8(s.data[0]) + 1
+	p := s.read(n)
+	if p == nil {
+		return s.fail("ReadVInt")
+	}
+	v := uint64(p[0]) &^ (0x80 >> uint(n-1))
+	for _, c := range p[1:] {
+		v = v<<8 | uint64(c)
+	}
+	if v == 1<<uint(7*n)-1 {
+		v = VIntUnknown
+	}
+	*out = v
+	return true
+}
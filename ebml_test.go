@@ -0,0 +1,77 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package littlebyte
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestVInt(t *testing.T) {
+	tests := []struct {
+		v    uint64
+		want []byte
+	}{
+		{0, []byte{0x80}},
+		{1, []byte{0x81}},
+		{126, []byte{0xfe}},
+		{127, []byte{0x40, 0x7f}},
+		{0x3ffe, []byte{0x7f, 0xfe}},
+		{0x3fff, []byte{0x20, 0x3f, 0xff}},
+		{maxVInt, []byte{0x01, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xfe}},
+		{VIntUnknown, []byte{0xff}},
+	}
+	for _, tt := range tests {
+		var b Builder
+		b.AddVInt(tt.v)
+		got, err := b.Bytes()
+		if err != nil || !bytes.Equal(got, tt.want) {
+			t.Errorf("AddVInt(%#x) = %x, %v; want %x, nil", tt.v, got, err, tt.want)
+			continue
+		}
+		s := NewString(got)
+		var v uint64
+		if !s.ReadVInt(&v) || v != tt.v || !s.Empty() {
+			t.Errorf("ReadVInt(%x) = %#x, want %#x", tt.want, v, tt.v)
+		}
+	}
+}
+
+func TestVIntTooLarge(t *testing.T) {
+	var b Builder
+	b.AddVInt(maxVInt + 1)
+	if _, err := b.Bytes(); err == nil {
+		t.Error("Bytes() error = nil, want error for value too large")
+	}
+}
+
+func TestReadVInt(t *testing.T) {
+	var v uint64
+	// Unknown size may be encoded in any width.
+	s := NewString([]byte{0x1f, 0xff, 0xff, 0xff})
+	if !s.ReadVInt(&v) || v != VIntUnknown {
+		t.Errorf("ReadVInt() = %#x, want VIntUnknown", v)
+	}
+	// Non-minimal encodings are accepted.
+	s = NewString([]byte{0x40, 0x01})
+	if !s.ReadVInt(&v) || v != 1 {
+		t.Errorf("ReadVInt() = %d, want 1", v)
+	}
+
+	for _, in := range [][]byte{
+		{},
+		{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08},
+		{0x40},
+		{0x01, 0xff},
+	} {
+		s := NewString(in)
+		if s.ReadVInt(&v) {
+			t.Errorf("ReadVInt(%x) = true, want false", in)
+		}
+		if s.Len() != len(in) {
+			t.Errorf("ReadVInt(%x) advanced on failure", in)
+		}
+	}
+}
@@ -16,7 +16,7 @@ func ExampleString_lengthPrefixed() {
 	// example, TLS). Imagine a 16-bit prefixed series of 8-bit prefixed
 	// strings.
 
-	input := littlebyte.String([]byte{12, 0, 5, 'h', 'e', 'l', 'l', 'o', 5, 'w', 'o', 'r', 'l', 'd'})
+	input := littlebyte.NewString([]byte{12, 0, 5, 'h', 'e', 'l', 'l', 'o', 5, 'w', 'o', 'r', 'l', 'd'})
 	var result []string
 
 	var values littlebyte.String
@@ -31,7 +31,7 @@ func ExampleString_lengthPrefixed() {
 			panic("bad format")
 		}
 
-		result = append(result, string(value))
+		result = append(result, string(value.Bytes()))
 	}
 
 	// Output: []string{"hello", "world"}
@@ -76,7 +76,7 @@ func ExampleBuilder_lengthPrefixOverflow() {
 	result, err := b.Bytes()
 	fmt.Printf("len=%d err=%s\n", len(result), err)
 
-	// Output: len=0 err=littlebyte: pending child length 256 exceeds 1-byte length prefix
+	// Output: len=0 err=littlebyte: pending child length 256 exceeds 1-byte length prefix at depth 1
 }
 
 func ExampleBuilderContinuation_errorHandling() {
@@ -0,0 +1,54 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package littlebyte
+
+import (
+	"errors"
+	"math"
+)
+
+// AddFixedPoint16 appends v as a little-endian, 16-bit signed fixed-point
+// number with fracBits fractional bits, such as Q8.8 for fracBits = 8. The
+// value is scaled by 2^fracBits and rounded to the nearest integer, with
+// halfway cases rounded away from zero. Values outside the representable
+// range saturate to the largest or smallest representable value. If v is NaN,
+// an error is set on the Builder. It panics if fracBits is not between 0 and
+// 15.
+func (b *Builder) AddFixedPoint16(v float64, fracBits int) {
+	if fracBits < 0 || fracBits > 15 {
+		panic("littlebyte: invalid number of fractional bits")
+	}
+	if b.err != nil {
+		return
+	}
+	if math.IsNaN(v) {
+		b.err = errors.New("littlebyte: cannot encode NaN as fixed-point")
+		return
+	}
+	x := math.Round(math.Ldexp(v, fracBits))
+	switch {
+	case x > math.MaxInt16:
+		x = math.MaxInt16
+	case x < math.MinInt16:
+		x = math.MinInt16
+	}
+	b.AddUint16(uint16(int16(x)))
+}
+
+// ReadFixedPoint16 decodes a little-endian, 16-bit signed fixed-point number
+// with fracBits fractional bits into out and advances over it. It reports
+// whether the read was successful. It panics if fracBits is not between 0 and
+// 15.
+func (s *String) ReadFixedPoint16(out *float64, fracBits int) bool {
+	if fracBits < 0 || fracBits > 15 {
+		panic("littlebyte: invalid number of fractional bits")
+	}
+	var v uint32
+	if !s.readUnsigned(&v, 2) {
+		return s.fail("ReadFixedPoint16")
+	}
+	*out = math.Ldexp(float64(int16(v)), -fracBits)
+	return true
+}
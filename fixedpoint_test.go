@@ -0,0 +1,61 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package littlebyte
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFixedPoint16(t *testing.T) {
+	tests := []struct {
+		v        float64
+		fracBits int
+		want     uint16
+		read     float64
+	}{
+		{1.5, 8, 0x0180, 1.5},
+		{-1.5, 8, 0xfe80, -1.5},
+		{0.001, 8, 0x0000, 0},
+		{0.5 / 256, 8, 0x0001, 1.0 / 256}, // Halfway rounds away from zero.
+		{127.998, 8, 0x7fff, 32767.0 / 256},
+		{1000, 8, 0x7fff, 32767.0 / 256}, // Saturates.
+		{-1000, 8, 0x8000, -128},
+		{math.Inf(1), 0, 0x7fff, 32767},
+		{-0.75, 15, 0xa000, -0.75},
+	}
+	for _, tt := range tests {
+		var b Builder
+		b.AddFixedPoint16(tt.v, tt.fracBits)
+		if err := builderBytesEq(&b, byte(tt.want), byte(tt.want>>8)); err != nil {
+			t.Errorf("AddFixedPoint16(%v, %d): %v", tt.v, tt.fracBits, err)
+			continue
+		}
+		s := NewString(b.BytesOrPanic())
+		var got float64
+		if !s.ReadFixedPoint16(&got, tt.fracBits) || got != tt.read {
+			t.Errorf("ReadFixedPoint16(%#04x, %d) = %v, want %v", tt.want, tt.fracBits, got, tt.read)
+		}
+	}
+
+	var b Builder
+	b.AddFixedPoint16(math.NaN(), 8)
+	if _, err := b.Bytes(); err == nil {
+		t.Error("Bytes() error = nil after AddFixedPoint16(NaN), want error")
+	}
+
+	s := NewString([]byte{1})
+	var got float64
+	if s.ReadFixedPoint16(&got, 8) {
+		t.Error("ReadFixedPoint16() = true on short input, want false")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("recover() = nil, want error; AddFixedPoint16() did not panic")
+		}
+	}()
+	b.AddFixedPoint16(1, 16)
+}
@@ -0,0 +1,51 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package littlebyte
+
+// AddSOffset appends a FlatBuffers soffset_t, a little-endian, 32-bit signed
+// offset, such as the offset from a table to its vtable.
+func (b *Builder) AddSOffset(v int32) {
+	b.AddUint32(uint32(v))
+}
+
+// AddUOffset appends a FlatBuffers uoffset_t, a little-endian, 32-bit
+// unsigned offset, such as the offset to a table, vector or string.
+func (b *Builder) AddUOffset(v uint32) {
+	b.AddUint32(v)
+}
+
+// AddVOffset appends a FlatBuffers voffset_t, a little-endian, 16-bit
+// unsigned offset, as used for the entries of a vtable.
+func (b *Builder) AddVOffset(v uint16) {
+	b.AddUint16(v)
+}
+
+// ReadSOffset decodes a FlatBuffers soffset_t into out and advances over it.
+// It reports whether the read was successful.
+func (s *String) ReadSOffset(out *int32) bool {
+	var v uint32
+	if !s.readUnsigned(&v, 4) {
+		return s.fail("ReadSOffset")
+	}
+	*out = int32(v)
+	return true
+}
+
+// ReadUOffset decodes a FlatBuffers uoffset_t into out and advances over it.
+// It reports whether the read was successful.
+func (s *String) ReadUOffset(out *uint32) bool {
+	return s.readUnsigned(out, 4) || s.fail("ReadUOffset")
+}
+
+// ReadVOffset decodes a FlatBuffers voffset_t into out and advances over it.
+// It reports whether the read was successful.
+func (s *String) ReadVOffset(out *uint16) bool {
+	var v uint32
+	if !s.readUnsigned(&v, 2) {
+		return s.fail("ReadVOffset")
+	}
+	*out = uint16(v)
+	return true
+}
@@ -0,0 +1,33 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package littlebyte
+
+import "testing"
+
+func TestFlatBuffersOffsets(t *testing.T) {
+	var b Builder
+	b.AddSOffset(-8)
+	b.AddUOffset(0x01020304)
+	b.AddVOffset(0x0506)
+	if err := builderBytesEq(&b, 0xf8, 0xff, 0xff, 0xff, 4, 3, 2, 1, 6, 5); err != nil {
+		t.Error(err)
+	}
+
+	s := NewString(b.BytesOrPanic())
+	var (
+		soff int32
+		uoff uint32
+		voff uint16
+	)
+	if !s.ReadSOffset(&soff) || !s.ReadUOffset(&uoff) || !s.ReadVOffset(&voff) {
+		t.Fatal("parsing failed")
+	}
+	if soff != -8 || uoff != 0x01020304 || voff != 0x0506 {
+		t.Errorf("soff, uoff, voff = %d, %#x, %#x; want -8, 0x01020304, 0x0506", soff, uoff, voff)
+	}
+	if s.ReadVOffset(&voff) || s.ReadUOffset(&uoff) || s.ReadSOffset(&soff) {
+		t.Error("read past the end succeeded")
+	}
+}
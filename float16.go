@@ -0,0 +1,93 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package littlebyte
+
+import "math"
+
+// AddFloat16 converts f to an IEEE 754 half-precision value and appends it as
+// a little-endian, 16-bit value. f is rounded to the nearest representable
+// value, with ties to even. Values too large in magnitude become infinities,
+// values too small become subnormals or zeros, and NaNs remain NaNs, keeping
+// the high bits of their payload.
+func (b *Builder) AddFloat16(f float32) {
+	b.AddUint16(float32ToHalf(f))
+}
+
+// ReadFloat16 decodes a little-endian, IEEE 754 half-precision value into out
+// and advances over it. Every half-precision value, including subnormals,
+// infinities and NaNs, is exactly representable as a float32. It reports
+// whether the read was successful.
+func (s *String) ReadFloat16(out *float32) bool {
+	var v uint32
+	if !s.readUnsigned(&v, 2) {
+		return s.fail("ReadFloat16")
+	}
+	*out = halfToFloat32(uint16(v))
+	return true
+}
+
+func float32ToHalf(f float32) uint16 {
+	bits := math.Float32bits(f)
+	sign := uint16(bits>>16) & 0x8000
+	exp := int(bits>>23) & 0xff
+	mant := bits & 0x7fffff
+
+	if exp == 0xff {
+		if mant != 0 {
+			// Keep the payload's high bits, and make sure it is still a NaN.
+			return sign | 0x7e00 | uint16(mant>>13)
+		}
+		return sign | 0x7c00
+	}
+
+	e := exp - 127 + 15
+	if e >= 0x1f {
+		return sign | 0x7c00
+	}
+	shift := uint(13)
+	if e <= 0 {
+		if e < -10 {
+			// Less than half the smallest subnormal, so it rounds to zero.
+			return sign
+		}
+		// Subnormal: include the implicit leading bit and shift it into the
+		// mantissa, losing more precision.
+		mant |= 0x800000
+		shift = uint(14 - e)
+		e = 0
+	}
+
+	// Round to nearest, ties to even. A carry out of the mantissa correctly
+	// increments the exponent, possibly to infinity.
+	h := uint16(e)<<10 | uint16(mant>>shift)
+	half := uint32(1) << (shift - 1)
+	rem := mant & (1<<shift - 1)
+	if rem > half || rem == half && h&1 != 0 {
+		h++
+	}
+	return sign | h
+}
+
+func halfToFloat32(h uint16) float32 {
+	sign := uint32(h&0x8000) << 16
+	exp := int(h>>10) & 0x1f
+	mant := uint32(h & 0x3ff)
+
+	switch {
+	case exp == 0x1f:
+		return math.Float32frombits(sign | 0x7f800000 | mant<<13)
+	case exp == 0 && mant == 0:
+		return math.Float32frombits(sign)
+	case exp == 0:
+		// Subnormal: normalize it, since it is within float32's normal range.
+		exp = 1
+		for mant&0x400 == 0 {
+			mant <<= 1
+			exp--
+		}
+		mant &= 0x3ff
+	}
+	return math.Float32frombits(sign | uint32(exp-15+127)<<23 | mant<<13)
+}
@@ -0,0 +1,85 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package littlebyte
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestFloat16(t *testing.T) {
+	for _, test := range []struct {
+		f    float32
+		want uint16
+	}{
+		{0, 0x0000},
+		{float32(math.Copysign(0, -1)), 0x8000},
+		{1, 0x3c00},
+		{-2, 0xc000},
+		{0.333251953125, 0x3555},
+		{65504, 0x7bff},                    // largest finite
+		{65519, 0x7bff},                    // rounds down
+		{65520, 0x7c00},                    // tie rounds to even, which overflows
+		{1e10, 0x7c00},                     // overflow
+		{float32(math.Inf(-1)), 0xfc00},    // infinity
+		{1 + 1.0/2048, 0x3c00},             // tie rounds to even, down
+		{1 + 3.0/2048, 0x3c02},             // tie rounds to even, up
+		{1 + 1.0/2048 + 1.0/65536, 0x3c01}, // above tie rounds up
+		{1.0 / (1 << 14), 0x0400},          // smallest normal
+		{1.0 / (1 << 24), 0x0001},          // smallest subnormal
+		{1023.0 / (1 << 24), 0x03ff},       // largest subnormal
+		{1023.5 / (1 << 24), 0x0400},       // tie rounds up into normal range
+		{1.0 / (1 << 25), 0x0000},          // tie rounds to even, to zero
+		{1.5 / (1 << 25), 0x0001},          // above tie rounds up
+		{-2.5 / (1 << 24), 0x8002},         // tie rounds to even
+		{1.0 / (1 << 26), 0x0000},          // underflow
+		{math.Float32frombits(1), 0x0000},  // float32 subnormal
+	} {
+		var b Builder
+		b.AddFloat16(test.f)
+		if err := builderBytesEq(&b, uint8(test.want), uint8(test.want>>8)); err != nil {
+			t.Errorf("AddFloat16(%g): %v", test.f, err)
+		}
+	}
+
+	// Every value round trips through float32 exactly.
+	for i := 0; i < 1<<16; i++ {
+		h := uint16(i)
+		s := NewString([]byte{uint8(h), uint8(h >> 8)})
+		var f float32
+		if !s.ReadFloat16(&f) {
+			t.Fatal("ReadFloat16() = false, want true")
+		}
+		isNaN := h&0x7c00 == 0x7c00 && h&0x3ff != 0
+		if isNaN != math.IsNaN(float64(f)) {
+			t.Errorf("ReadFloat16(%#04x) = %g, NaN-ness mismatch", h, f)
+			continue
+		}
+		if got := float32ToHalf(f); !isNaN && got != h {
+			t.Errorf("float32ToHalf(ReadFloat16(%#04x)) = %#04x", h, got)
+		}
+	}
+
+	var f float32
+	s := NewString([]byte{0x00, 0x3c, 0x01})
+	if !s.ReadFloat16(&f) || f != 1 {
+		t.Errorf("ReadFloat16() = %g, want 1", f)
+	}
+	if s.ReadFloat16(&f) {
+		t.Error("ReadFloat16() = true on short input, want false")
+	}
+	if got, want := s.Reason(), "littlebyte: ReadFloat16 failed"; !strings.HasPrefix(got, want) {
+		t.Errorf("Reason() = %q, want it to start with %q", got, want)
+	}
+
+	nan := float32ToHalf(float32(math.NaN()))
+	if nan&0x7c00 != 0x7c00 || nan&0x3ff == 0 {
+		t.Errorf("float32ToHalf(NaN) = %#04x, want a NaN", nan)
+	}
+	if got := float32ToHalf(math.Float32frombits(0x7f800001)); got&0x3ff == 0 {
+		t.Errorf("float32ToHalf(signaling NaN) = %#04x, want a NaN", got)
+	}
+}
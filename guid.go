@@ -0,0 +1,38 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package littlebyte
+
+// guidSwap lists, for each byte of a GUID in Microsoft's layout, the index of
+// the corresponding byte of the canonical UUID. The first three fields
+// (4, 2 and 2 bytes) are byte-swapped; the last 8 bytes are unchanged.
+var guidSwap = [16]int{3, 2, 1, 0, 5, 4, 7, 6, 8, 9, 10, 11, 12, 13, 14, 15}
+
+// AddGUID appends a GUID in the layout used by Microsoft, as in the Windows
+// GUID structure. g holds the canonical UUID bytes of RFC 4122, in the order
+// they appear in the textual form: 00112233-4455-6677-8899-aabbccddeeff is
+// g = [16]byte{0x00, 0x11, 0x22, ...}. The first three fields are written
+// little-endian and the remaining 8 bytes as they are, giving
+// 33 22 11 00 55 44 77 66 88 99 aa bb cc dd ee ff.
+func (b *Builder) AddGUID(g [16]byte) {
+	var buf [16]byte
+	for i, j := range guidSwap {
+		buf[i] = g[j]
+	}
+	b.add(buf[:]...)
+}
+
+// ReadGUID decodes a GUID in the layout used by Microsoft, as written by
+// AddGUID, into out as canonical UUID bytes and advances over it. It reports
+// whether the read was successful.
+func (s *String) ReadGUID(out *[16]byte) bool {
+	v := s.read(16)
+	if v == nil {
+		return s.fail("ReadGUID")
+	}
+	for i, j := range guidSwap {
+		out[j] = v[i]
+	}
+	return true
+}
@@ -0,0 +1,29 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package littlebyte
+
+import "testing"
+
+func TestGUID(t *testing.T) {
+	// 00112233-4455-6677-8899-aabbccddeeff
+	g := [16]byte{0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88, 0x99, 0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff}
+	var b Builder
+	b.AddGUID(g)
+	if err := builderBytesEq(&b, 0x33, 0x22, 0x11, 0x00, 0x55, 0x44, 0x77, 0x66, 0x88, 0x99, 0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff); err != nil {
+		t.Error(err)
+	}
+
+	s := NewString(b.BytesOrPanic())
+	var got [16]byte
+	if !s.ReadGUID(&got) {
+		t.Fatal("ReadGUID() = false, want true")
+	}
+	if got != g {
+		t.Errorf("ReadGUID() = %x, want %x", got, g)
+	}
+	if s.ReadGUID(&got) {
+		t.Error("ReadGUID() = true on empty input, want false")
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package littlebyte
+
+import "math"
+
+// MessagePack integer type tags.
+const (
+	msgpackUint8  = 0xcc
+	msgpackUint16 = 0xcd
+	msgpackUint32 = 0xce
+	msgpackUint64 = 0xcf
+	msgpackInt8   = 0xd0
+	msgpackInt16  = 0xd1
+	msgpackInt32  = 0xd2
+	msgpackInt64  = 0xd3
+)
+
+// addMsgpack appends a MessagePack type tag followed by the n low bytes of v
+// in big-endian order, as MessagePack requires.
+func (b *Builder) addMsgpack(tag uint8, v uint64, n int) {
+	var buf [9]byte
+	buf[0] = tag
+	for i := n; i > 0; i-- {
+		buf[i] = byte(v)
+		v >>= 8
+	}
+	b.add(buf[:n+1]...)
+}
+
+// AddMsgpackUint appends v as a MessagePack integer, using the shortest
+// unsigned representation: a positive fixint or a uint 8, 16, 32 or 64.
+func (b *Builder) AddMsgpackUint(v uint64) {
+	switch {
+	case v < 0x80:
+		b.AddUint8(uint8(v))
+	case v <= math.MaxUint8:
+		b.addMsgpack(msgpackUint8, v, 1)
+	case v <= math.MaxUint16:
+		b.addMsgpack(msgpackUint16, v, 2)
+	case v <= math.MaxUint32:
+		b.addMsgpack(msgpackUint32, v, 4)
+	default:
+		b.addMsgpack(msgpackUint64, v, 8)
+	}
+}
+
+// AddMsgpackInt appends v as a MessagePack integer, using the shortest
+// representation. Non-negative values are encoded as by AddMsgpackUint;
+// negative values are encoded as a negative fixint or an int 8, 16, 32 or 64.
+func (b *Builder) AddMsgpackInt(v int64) {
+	switch {
+	case v >= 0:
+		b.AddMsgpackUint(uint64(v))
+	case v >= -32:
+		b.AddUint8(uint8(v))
+	case v >= math.MinInt8:
+		b.addMsgpack(msgpackInt8, uint64(v), 1)
+	case v >= math.MinInt16:
+		b.addMsgpack(msgpackInt16, uint64(v), 2)
+	case v >= math.MinInt32:
+		b.addMsgpack(msgpackInt32, uint64(v), 4)
+	default:
+		b.addMsgpack(msgpackInt64, uint64(v), 8)
+	}
+}
+
+// readMsgpack decodes a MessagePack integer of any representation and
+// advances over it. If the representation is signed, negative is set to
+// whether the value is negative and v holds the value's two's complement;
+// otherwise negative is false.
+func (s *String) readMsgpack(v *uint64, negative *bool) bool {
+	var tag uint32
+	if !s.readUnsigned(&tag, 1) {
+		return false
+	}
+	n, signed := 0, false
+	switch {
+	case tag < 0x80:
+		*v, *negative = uint64(tag), false
+		return true
+	case tag >= 0xe0:
+		*v, *negative = uint64(int64(int8(tag))), true
+		return true
+	case tag >= msgpackUint8 && tag <= msgpackUint64:
+		n = 1 << (tag - msgpackUint8)
+	case tag >= msgpackInt8 && tag <= msgpackInt64:
+		n, signed = 1<<(tag-msgpackInt8), true
+	default:
+		return false
+	}
+	p := s.read(n)
+	if p == nil {
+		return false
+	}
+	var result uint64
+	for _, c := range p {
+		result = result<<8 | uint64(c)
+	}
+	if signed {
+		// Sign-extend the value to 64 bits.
+		shift := uint(64 - 8*n)
+		result = uint64(int64(result<<shift) >> shift)
+	}
+	*v, *negative = result, signed && int64(result) < 0
+	return true
+}
+
+// ReadMsgpackUint decodes a non-negative MessagePack integer, in any
+// representation, into out and advances over it. It reports whether the read
+// was successful. The read fails if the value is negative.
+func (s *String) ReadMsgpackUint(out *uint64) bool {
+	var v uint64
+	var negative bool
+	t := *s
+	if !t.readMsgpack(&v, &negative) || negative {
+		return s.fail("ReadMsgpackUint")
+	}
+	*s = t
+	*out = v
+	return true
+}
+
+// ReadMsgpackInt decodes a MessagePack integer, in any representation, into
+// out and advances over it. It reports whether the read was successful. The
+// read fails if the value does not fit in an int64.
+func (s *String) ReadMsgpackInt(out *int64) bool {
+	var v uint64
+	var negative bool
+	t := *s
+	if !t.readMsgpack(&v, &negative) || (!negative && v > math.MaxInt64) {
+		return s.fail("ReadMsgpackInt")
+	}
+	*s = t
+	*out = int64(v)
+	return true
+}
@@ -0,0 +1,92 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package littlebyte
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMsgpackUint(t *testing.T) {
+	for _, test := range []struct {
+		v    uint64
+		want []byte
+	}{
+		{0, []byte{0}},
+		{0x7f, []byte{0x7f}},
+		{0x80, []byte{0xcc, 0x80}},
+		{0x100, []byte{0xcd, 0x01, 0x00}},
+		{0x10000, []byte{0xce, 0x00, 0x01, 0x00, 0x00}},
+		{math.MaxUint64, []byte{0xcf, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}},
+	} {
+		var b Builder
+		b.AddMsgpackUint(test.v)
+		if err := builderBytesEq(&b, test.want...); err != nil {
+			t.Errorf("AddMsgpackUint(%d): %v", test.v, err)
+		}
+
+		s := NewString(b.BytesOrPanic())
+		var v uint64
+		if !s.ReadMsgpackUint(&v) || v != test.v || !s.Empty() {
+			t.Errorf("ReadMsgpackUint() = %d, want %d", v, test.v)
+		}
+	}
+}
+
+func TestMsgpackInt(t *testing.T) {
+	for _, test := range []struct {
+		v    int64
+		want []byte
+	}{
+		{0, []byte{0}},
+		{127, []byte{0x7f}},
+		{200, []byte{0xcc, 0xc8}},
+		{-1, []byte{0xff}},
+		{-32, []byte{0xe0}},
+		{-33, []byte{0xd0, 0xdf}},
+		{math.MinInt8, []byte{0xd0, 0x80}},
+		{math.MinInt8 - 1, []byte{0xd1, 0xff, 0x7f}},
+		{math.MinInt16, []byte{0xd1, 0x80, 0x00}},
+		{math.MinInt32, []byte{0xd2, 0x80, 0x00, 0x00, 0x00}},
+		{math.MinInt64, []byte{0xd3, 0x80, 0, 0, 0, 0, 0, 0, 0}},
+		{math.MaxInt64, []byte{0xcf, 0x7f, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}},
+	} {
+		var b Builder
+		b.AddMsgpackInt(test.v)
+		if err := builderBytesEq(&b, test.want...); err != nil {
+			t.Errorf("AddMsgpackInt(%d): %v", test.v, err)
+		}
+
+		s := NewString(b.BytesOrPanic())
+		var v int64
+		if !s.ReadMsgpackInt(&v) || v != test.v || !s.Empty() {
+			t.Errorf("ReadMsgpackInt() = %d, want %d", v, test.v)
+		}
+	}
+}
+
+func TestMsgpackInvalid(t *testing.T) {
+	var u uint64
+	var i int64
+
+	// Non-negative values in a signed representation are accepted.
+	s := NewString([]byte{0xd1, 0x01, 0x00})
+	if !s.ReadMsgpackUint(&u) || u != 256 {
+		t.Errorf("ReadMsgpackUint() = %d, want 256", u)
+	}
+
+	for _, input := range [][]byte{{0xff}, {0xd0, 0x80}} {
+		s := NewString(input)
+		if s.ReadMsgpackUint(&u) {
+			t.Errorf("ReadMsgpackUint(%x) = true, want false", input)
+		}
+	}
+	for _, input := range [][]byte{{0xcf, 0x80, 0, 0, 0, 0, 0, 0, 0}, {0xc0}, {0xcd, 0x01}, {}} {
+		s := NewString(input)
+		if s.ReadMsgpackInt(&i) {
+			t.Errorf("ReadMsgpackInt(%x) = true, want false", input)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package littlebyte
+
+// Markers for MySQL length-encoded integers.
+const (
+	lenEncNull   = 0xfb
+	lenEncUint16 = 0xfc
+	lenEncUint24 = 0xfd
+	lenEncUint64 = 0xfe
+)
+
+// AddLenEncInt appends v as a MySQL length-encoded integer, using the shortest
+// encoding: a single byte for values less than 251, otherwise a marker byte
+// followed by a little-endian, 16-, 24- or 64-bit value.
+func (b *Builder) AddLenEncInt(v uint64) {
+	switch {
+	case v < lenEncNull:
+		b.AddUint8(uint8(v))
+	case v < 1<<16:
+		b.AddUint8(lenEncUint16)
+		b.AddUint16(uint16(v))
+	case v < 1<<24:
+		b.AddUint8(lenEncUint24)
+		b.AddUint24(uint32(v))
+	default:
+		b.AddUint8(lenEncUint64)
+		b.AddUint32(uint32(v))
+		b.AddUint32(uint32(v >> 32))
+	}
+}
+
+// AddLenEncNull appends the MySQL length-encoded NULL marker, 0xfb.
+func (b *Builder) AddLenEncNull() {
+	b.AddUint8(lenEncNull)
+}
+
+// readLenEnc decodes a MySQL length-encoded integer, or the NULL marker, and
+// advances over it. It reports whether the read was successful.
+func (s *String) readLenEnc(out *uint64, null *bool) bool {
+	var marker uint32
+	if !s.readUnsigned(&marker, 1) {
+		return false
+	}
+	var lo, hi uint32
+	switch marker {
+	case lenEncNull:
+		*null = true
+		return true
+	case lenEncUint16:
+		if !s.readUnsigned(&lo, 2) {
+			return false
+		}
+	case lenEncUint24:
+		if !s.readUnsigned(&lo, 3) {
+			return false
+		}
+	case lenEncUint64:
+		if !s.readUnsigned(&lo, 4) || !s.readUnsigned(&hi, 4) {
+			return false
+		}
+	case 0xff:
+		return false
+	default:
+		lo = marker
+	}
+	*out = uint64(hi)<<32 | uint64(lo)
+	*null = false
+	return true
+}
+
+// ReadLenEncInt decodes a MySQL length-encoded integer into out and advances
+// over it. It reports whether the read was successful. The read fails if the
+// value is NULL; use ReadLenEncNullableInt to accept NULL values.
+func (s *String) ReadLenEncInt(out *uint64) bool {
+	var null bool
+	t := *s
+	if !t.readLenEnc(out, &null) || null {
+		return s.fail("ReadLenEncInt")
+	}
+	*s = t
+	return true
+}
+
+// ReadLenEncNullableInt decodes a MySQL length-encoded integer, which may be
+// NULL, and advances over it. If the value is NULL, null is set to true and
+// out is unchanged; otherwise null is set to false and the value is stored in
+// out. It reports whether the read was successful.
+func (s *String) ReadLenEncNullableInt(out *uint64, null *bool) bool {
+	return s.readLenEnc(out, null) || s.fail("ReadLenEncNullableInt")
+}
+
+// AddLenEncString appends v as a MySQL length-encoded string: its length as a
+// length-encoded integer, followed by its bytes. Use AddLenEncNull to append a
+// NULL value.
+func (b *Builder) AddLenEncString(v []byte) {
+	b.AddLenEncInt(uint64(len(v)))
+	b.AddBytes(v)
+}
+
+// ReadLenEncString reads a MySQL length-encoded string, which may be NULL,
+// into out and advances over it. If the value is NULL, out is set to nil;
+// otherwise it is set to a non-nil slice, which is empty for an empty string.
+// It reports whether the read was successful; if not, s is unchanged.
+func (s *String) ReadLenEncString(out *[]byte) bool {
+	t := *s
+	var length uint64
+	var null bool
+	if !t.readLenEnc(&length, &null) {
+		return s.fail("ReadLenEncString")
+	}
+	if null {
+		*out = nil
+		*s = t
+		return true
+	}
+	if length > uint64(len(t.data)) {
+		return s.fail("ReadLenEncString")
+	}
+	*out = t.read(int(length))
+	*s = t
+	return true
+}
@@ -0,0 +1,105 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package littlebyte
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestLenEncInt(t *testing.T) {
+	for _, test := range []struct {
+		v    uint64
+		want []byte
+	}{
+		{0, []byte{0}},
+		{250, []byte{250}},
+		{251, []byte{0xfc, 251, 0}},
+		{0xffff, []byte{0xfc, 0xff, 0xff}},
+		{0x10000, []byte{0xfd, 0, 0, 1}},
+		{0xffffff, []byte{0xfd, 0xff, 0xff, 0xff}},
+		{0x1000000, []byte{0xfe, 0, 0, 0, 1, 0, 0, 0, 0}},
+		{0x0102030405060708, []byte{0xfe, 8, 7, 6, 5, 4, 3, 2, 1}},
+	} {
+		var b Builder
+		b.AddLenEncInt(test.v)
+		if err := builderBytesEq(&b, test.want...); err != nil {
+			t.Errorf("AddLenEncInt(%#x): %v", test.v, err)
+		}
+
+		s := NewString(b.BytesOrPanic())
+		var v uint64
+		if !s.ReadLenEncInt(&v) || v != test.v || !s.Empty() {
+			t.Errorf("ReadLenEncInt(): v = %#x, s.Len() = %d; want %#x, 0", v, s.Len(), test.v)
+		}
+	}
+}
+
+func TestLenEncIntNull(t *testing.T) {
+	var b Builder
+	b.AddLenEncNull()
+	b.AddLenEncInt(7)
+	s := NewString(b.BytesOrPanic())
+
+	var v uint64 = 42
+	var null bool
+	if s.ReadLenEncInt(&v) {
+		t.Error("ReadLenEncInt() = true for NULL, want false")
+	}
+	if s.Len() != 2 {
+		t.Errorf("s.Len() = %d, want 2", s.Len())
+	}
+	if !s.ReadLenEncNullableInt(&v, &null) || !null || v != 42 {
+		t.Errorf("ReadLenEncNullableInt(): v, null = %d, %v; want 42, true", v, null)
+	}
+	if !s.ReadLenEncNullableInt(&v, &null) || null || v != 7 {
+		t.Errorf("ReadLenEncNullableInt(): v, null = %d, %v; want 7, false", v, null)
+	}
+
+	for _, input := range [][]byte{{0xff}, {0xfc, 1}, {0xfd, 1, 2}, {0xfe, 1, 2, 3, 4, 5, 6, 7}, {}} {
+		s := NewString(input)
+		if s.ReadLenEncNullableInt(&v, &null) {
+			t.Errorf("ReadLenEncNullableInt(%x) = true, want false", input)
+		}
+	}
+}
+
+func TestLenEncString(t *testing.T) {
+	long := bytes.Repeat([]byte{'x'}, 300)
+	var b Builder
+	b.AddLenEncString([]byte("hello"))
+	b.AddLenEncString(nil)
+	b.AddLenEncNull()
+	b.AddLenEncString(long)
+
+	s := NewString(b.BytesOrPanic())
+	var hello, empty, null, got []byte
+	if !s.ReadLenEncString(&hello) || !s.ReadLenEncString(&empty) || !s.ReadLenEncString(&null) || !s.ReadLenEncString(&got) {
+		t.Fatal("parsing failed")
+	}
+	if string(hello) != "hello" {
+		t.Errorf("hello = %q, want %q", hello, "hello")
+	}
+	if empty == nil || len(empty) != 0 {
+		t.Errorf("empty = %#v, want non-nil empty slice", empty)
+	}
+	if null != nil {
+		t.Errorf("null = %#v, want nil", null)
+	}
+	if !bytes.Equal(got, long) {
+		t.Errorf("len(got) = %d, want %d", len(got), len(long))
+	}
+	if !s.Empty() {
+		t.Errorf("s.Len() = %d, want 0", s.Len())
+	}
+
+	s = NewString([]byte{5, 'h', 'e'})
+	if s.ReadLenEncString(&got) {
+		t.Error("ReadLenEncString() = true on truncated input, want false")
+	}
+	if s.Len() != 3 {
+		t.Errorf("s.Len() = %d after failure, want 3", s.Len())
+	}
+}
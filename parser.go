@@ -0,0 +1,127 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package littlebyte
+
+import "fmt"
+
+// A Parser wraps a String and records the first read that fails. After a
+// failure, subsequent reads are ignored and leave their outputs unchanged.
+// This allows a sequence of reads to be performed and checked for errors once,
+// via Err, in the same way that a Builder defers its errors to Bytes.
+type Parser struct {
+	err  error
+	s    String
+	orig String
+}
+
+// NewParser creates a Parser that reads from s.
+func NewParser(s String) *Parser {
+	return &Parser{
+		s:    s,
+		orig: s,
+	}
+}
+
+// Err returns the error recorded by the first failed read, or nil if all reads
+// have succeeded.
+func (p *Parser) Err() error {
+	return p.err
+}
+
+// SetError sets the value to be returned from Err. Reads performed after
+// calling SetError are ignored.
+func (p *Parser) SetError(err error) {
+	p.err = err
+}
+
+// Remaining returns the bytes that have not yet been read.
+func (p *Parser) Remaining() String {
+	return p.s
+}
+
+// check records a failure of the named read if ok is false.
+func (p *Parser) check(ok bool, name string) {
+	if !ok && p.err == nil {
+		p.err = fmt.Errorf("littlebyte: %s failed at offset %d", name, p.s.ConsumedSince(p.orig))
+	}
+}
+
+// Skip advances the Parser by n bytes.
+func (p *Parser) Skip(n int) {
+	if p.err == nil {
+		p.check(p.s.Skip(n), "Skip")
+	}
+}
+
+// ReadUint8 decodes an 8-bit value into out and advances over it.
+func (p *Parser) ReadUint8(out *uint8) {
+	if p.err == nil {
+		p.check(p.s.ReadUint8(out), "ReadUint8")
+	}
+}
+
+// ReadUint16 decodes a little-endian, 16-bit value into out and advances over it.
+func (p *Parser) ReadUint16(out *uint16) {
+	if p.err == nil {
+		p.check(p.s.ReadUint16(out), "ReadUint16")
+	}
+}
+
+// ReadUint24 decodes a little-endian, 24-bit value into out and advances over it.
+func (p *Parser) ReadUint24(out *uint32) {
+	if p.err == nil {
+		p.check(p.s.ReadUint24(out), "ReadUint24")
+	}
+}
+
+// ReadUint32 decodes a little-endian, 32-bit value into out and advances over it.
+func (p *Parser) ReadUint32(out *uint32) {
+	if p.err == nil {
+		p.check(p.s.ReadUint32(out), "ReadUint32")
+	}
+}
+
+// ReadUint8LengthPrefixed reads the content of an 8-bit length-prefixed value
+// into out and advances over it.
+func (p *Parser) ReadUint8LengthPrefixed(out *String) {
+	if p.err == nil {
+		p.check(p.s.ReadUint8LengthPrefixed(out), "ReadUint8LengthPrefixed")
+	}
+}
+
+// ReadUint16LengthPrefixed reads the content of a little-endian, 16-bit
+// length-prefixed value into out and advances over it.
+func (p *Parser) ReadUint16LengthPrefixed(out *String) {
+	if p.err == nil {
+		p.check(p.s.ReadUint16LengthPrefixed(out), "ReadUint16LengthPrefixed")
+	}
+}
+
+// ReadUint24LengthPrefixed reads the content of a little-endian, 24-bit
+// length-prefixed value into out and advances over it.
+func (p *Parser) ReadUint24LengthPrefixed(out *String) {
+	if p.err == nil {
+		p.check(p.s.ReadUint24LengthPrefixed(out), "ReadUint24LengthPrefixed")
+	}
+}
+
+// ReadBytes reads n bytes into out and advances over them.
+func (p *Parser) ReadBytes(out *[]byte, n int) {
+	if p.err == nil {
+		p.check(p.s.ReadBytes(out, n), "ReadBytes")
+	}
+}
+
+// CopyBytes copies len(out) bytes into out and advances over them.
+func (p *Parser) CopyBytes(out []byte) {
+	if p.err == nil {
+		p.check(p.s.CopyBytes(out), "CopyBytes")
+	}
+}
+
+// Empty reports whether the Parser has no bytes left to read.
+func (p *Parser) Empty() bool {
+	return p.s.Empty()
+}
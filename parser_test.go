@@ -0,0 +1,64 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package littlebyte
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParser(t *testing.T) {
+	p := NewParser(NewString([]byte{23, 0xfe, 0xff, 2, 5, 6}))
+	var (
+		x     uint8
+		y     uint16
+		child String
+	)
+	p.ReadUint8(&x)
+	p.ReadUint16(&y)
+	p.ReadUint8LengthPrefixed(&child)
+	if err := p.Err(); err != nil {
+		t.Fatalf("Err() = %v, want nil", err)
+	}
+	if x != 23 || y != 0xfffe || child.Len() != 2 {
+		t.Errorf("x, y, child.Len() = %d, %d, %d; want 23, 65534, 2", x, y, child.Len())
+	}
+	if !p.Empty() {
+		t.Errorf("Empty() = false, want true")
+	}
+}
+
+func TestParserError(t *testing.T) {
+	p := NewParser(NewString([]byte{1, 2, 3}))
+	var (
+		x uint16
+		y uint32
+		z uint8
+	)
+	p.ReadUint16(&x)
+	p.ReadUint32(&y) // fails
+	p.ReadUint8(&z)  // ignored
+	err := p.Err()
+	if err == nil {
+		t.Fatal("Err() = nil, want error")
+	}
+	const want = "littlebyte: ReadUint32 failed at offset 2"
+	if s := err.Error(); s != want {
+		t.Errorf("Err() = %q, want %q", s, want)
+	}
+	if x != 0x0201 || y != 0 || z != 0 {
+		t.Errorf("x, y, z = %d, %d, %d; want 513, 0, 0", x, y, z)
+	}
+	if n := p.Remaining().Len(); n != 1 {
+		t.Errorf("Remaining().Len() = %d, want 1", n)
+	}
+
+	p = NewParser(NewString([]byte{1, 2, 3}))
+	p.SetError(errors.New("TestParserError"))
+	p.ReadUint8(&z)
+	if z != 0 {
+		t.Errorf("z = %d, want 0", z)
+	}
+}
@@ -0,0 +1,44 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package littlebyte
+
+import "sync"
+
+var builderPool = sync.Pool{
+	New: func() interface{} { return new(Builder) },
+}
+
+// Acquire returns an empty Builder that allocates space as needed, reusing
+// one previously passed to Release if possible. Its buffer may have spare
+// capacity left over from earlier use.
+func Acquire() *Builder {
+	return builderPool.Get().(*Builder)
+}
+
+// Release resets b and returns it to the pool used by Acquire, keeping its
+// buffer for reuse if the Builder allocated it. Any error recorded in b is
+// discarded. A buffer that came from the caller, such as one passed to
+// NewBuilder or NewFixedBuilder that has not been outgrown, or one taken by
+// AddBytesNoCopy, is not kept, and neither is the buffer of a streaming
+// Builder.
+//
+// b must not be used after calling Release, and neither may any slice
+// returned by its Bytes method, since their contents will be overwritten when
+// the Builder is reused. Release panics if b has a pending child, or if b is a
+// child passed to a BuilderContinuation, whose buffer belongs to its parent.
+func Release(b *Builder) {
+	if b.child != nil {
+		panic("littlebyte: Release called while child is pending")
+	}
+	if b.offset != 0 || b.pendingLenLen != 0 || b.depth != 0 {
+		panic("littlebyte: Release called on a child Builder")
+	}
+	var result []byte
+	if b.owned && !b.fixedSize && b.stream == nil {
+		result = b.result[:0]
+	}
+	*b = Builder{result: result, owned: result != nil}
+	builderPool.Put(b)
+}
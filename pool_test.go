@@ -0,0 +1,102 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package littlebyte
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestAcquireRelease(t *testing.T) {
+	b := Acquire()
+	b.AddUint32(1)
+	b.SetError(errors.New("error"))
+	Release(b)
+
+	b = Acquire()
+	if b.Len() != 0 {
+		t.Errorf("Len() = %d after Acquire, want 0", b.Len())
+	}
+	b.AddUint8(2)
+	if err := builderBytesEq(b, 2); err != nil {
+		t.Error(err)
+	}
+	Release(b)
+
+	// A buffer passed to NewBuilder belongs to the caller, so it is not
+	// reused, but one allocated after outgrowing it is.
+	buf := make([]byte, 0, 8)
+	b = NewBuilder(buf)
+	b.AddUint8(1)
+	Release(b)
+	if b.result != nil {
+		t.Error("Release() kept a buffer passed to NewBuilder")
+	}
+	b = Acquire()
+	b.AddUint8(7)
+	if buf[:1][0] != 1 {
+		t.Errorf("buf[0] = %d after Release and Acquire, want 1", buf[:1][0])
+	}
+	Release(b)
+	b = NewBuilder(buf)
+	b.AddZeros(9)
+	Release(b)
+	if b.result == nil {
+		t.Error("Release() dropped a buffer allocated by the Builder")
+	}
+
+	// The buffer of a fixed-size Builder is not reused.
+	b = NewFixedBuilder(buf)
+	Release(b)
+	if b.fixedSize || b.result != nil {
+		t.Error("Release() kept the buffer of a fixed-size Builder")
+	}
+
+	b = Acquire()
+	b.AddUint8LengthPrefixed(func(c *Builder) {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("recover() = nil, want error; Release() did not panic")
+			}
+		}()
+		Release(b) // panics (child is pending)
+	})
+
+	// Releasing a child would discard its parent's bytes.
+	b = Acquire()
+	b.AddUint8(0xaa)
+	b.AddUint8LengthPrefixed(func(c *Builder) {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("recover() = nil, want error; Release() of a child did not panic")
+			}
+		}()
+		Release(c)
+	})
+	if err := builderBytesEq(b, 0xaa, 0); err != nil {
+		t.Error(err)
+	}
+}
+
+func BenchmarkBuilderPooled(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		bb := Acquire()
+		for j := 0; j < 256; j++ {
+			bb.AddUint32(uint32(j))
+		}
+		Release(bb)
+	}
+}
+
+func BenchmarkBuilderFresh(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		bb := new(Builder)
+		for j := 0; j < 256; j++ {
+			bb.AddUint32(uint32(j))
+		}
+	}
+}
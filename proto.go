@@ -0,0 +1,79 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package littlebyte
+
+import "fmt"
+
+// Limits of Protocol Buffers field tags.
+const (
+	maxProtoFieldNum = 1<<29 - 1
+	maxProtoWireType = 5
+)
+
+// AddProtoTag appends a Protocol Buffers field tag, (fieldNum<<3)|wireType, as
+// an unsigned varint. If fieldNum is not between 1 and 2^29-1 or wireType is
+// not between 0 and 5, an error is set on the Builder.
+func (b *Builder) AddProtoTag(fieldNum int, wireType int) {
+	if b.err != nil {
+		return
+	}
+	if fieldNum < 1 || fieldNum > maxProtoFieldNum || wireType < 0 || wireType > maxProtoWireType {
+		b.err = fmt.Errorf("littlebyte: invalid protobuf tag (field %d, wire type %d)", fieldNum, wireType)
+		return
+	}
+	b.AddUvarint(uint64(fieldNum)<<3 | uint64(wireType))
+}
+
+// ReadProtoTag decodes a Protocol Buffers field tag into fieldNum and wireType
+// and advances over it. It reports whether the read was successful. The read
+// fails if the field number or wire type are out of range.
+func (s *String) ReadProtoTag(fieldNum *int, wireType *int) bool {
+	var v uint64
+	t := *s
+	if !t.readUvarint(&v) || v>>3 < 1 || v>>3 > maxProtoFieldNum || v&7 > maxProtoWireType {
+		return s.fail("ReadProtoTag")
+	}
+	*s = t
+	*fieldNum = int(v >> 3)
+	*wireType = int(v & 7)
+	return true
+}
+
+// AddZigzag32 appends v in the zigzag encoding used for Protocol Buffers
+// sint32 values: (v<<1)^(v>>31), as an unsigned varint.
+func (b *Builder) AddZigzag32(v int32) {
+	b.AddUvarint(uint64(uint32(v<<1) ^ uint32(v>>31)))
+}
+
+// AddZigzag64 appends v in the zigzag encoding used for Protocol Buffers
+// sint64 values: (v<<1)^(v>>63), as an unsigned varint.
+func (b *Builder) AddZigzag64(v int64) {
+	b.AddUvarint(uint64(v<<1) ^ uint64(v>>63))
+}
+
+// ReadZigzag32 decodes a zigzag-encoded Protocol Buffers sint32 value into out
+// and advances over it. It reports whether the read was successful. The read
+// fails if the encoded value does not fit in 32 bits.
+func (s *String) ReadZigzag32(out *int32) bool {
+	var v uint64
+	t := *s
+	if !t.readUvarint(&v) || v > 1<<32-1 {
+		return s.fail("ReadZigzag32")
+	}
+	*s = t
+	*out = int32(uint32(v>>1)) ^ -int32(v&1)
+	return true
+}
+
+// ReadZigzag64 decodes a zigzag-encoded Protocol Buffers sint64 value into out
+// and advances over it. It reports whether the read was successful.
+func (s *String) ReadZigzag64(out *int64) bool {
+	var v uint64
+	if !s.readUvarint(&v) {
+		return s.fail("ReadZigzag64")
+	}
+	*out = int64(v>>1) ^ -int64(v&1)
+	return true
+}
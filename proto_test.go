@@ -0,0 +1,119 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package littlebyte
+
+import (
+	"math"
+	"testing"
+)
+
+func TestProtoTag(t *testing.T) {
+	for _, test := range []struct {
+		fieldNum, wireType int
+		want               []byte
+	}{
+		{1, 0, []byte{0x08}},
+		{2, 2, []byte{0x12}},
+		{15, 5, []byte{0x7d}},
+		{16, 0, []byte{0x80, 0x01}},
+		{maxProtoFieldNum, 1, []byte{0xf9, 0xff, 0xff, 0xff, 0x0f}},
+	} {
+		var b Builder
+		b.AddProtoTag(test.fieldNum, test.wireType)
+		if err := builderBytesEq(&b, test.want...); err != nil {
+			t.Errorf("AddProtoTag(%d, %d): %v", test.fieldNum, test.wireType, err)
+		}
+
+		s := NewString(b.BytesOrPanic())
+		var fieldNum, wireType int
+		if !s.ReadProtoTag(&fieldNum, &wireType) || fieldNum != test.fieldNum || wireType != test.wireType || !s.Empty() {
+			t.Errorf("ReadProtoTag() = %d, %d; want %d, %d", fieldNum, wireType, test.fieldNum, test.wireType)
+		}
+	}
+}
+
+func TestProtoTagInvalid(t *testing.T) {
+	for _, test := range []struct {
+		fieldNum, wireType int
+	}{
+		{0, 0},
+		{-1, 0},
+		{maxProtoFieldNum + 1, 0},
+		{1, 6},
+		{1, -1},
+	} {
+		var b Builder
+		b.AddProtoTag(test.fieldNum, test.wireType)
+		if _, err := b.Bytes(); err == nil {
+			t.Errorf("AddProtoTag(%d, %d): Bytes() error = nil, want error", test.fieldNum, test.wireType)
+		}
+	}
+
+	for _, input := range [][]byte{{0x06}, {0x0e}, {0x80, 0x80, 0x80, 0x80, 0x20}, {0x80}} {
+		s := NewString(input)
+		var fieldNum, wireType int
+		if s.ReadProtoTag(&fieldNum, &wireType) {
+			t.Errorf("ReadProtoTag(%x) = true, want false", input)
+		}
+	}
+}
+
+func TestZigzag32(t *testing.T) {
+	for _, test := range []struct {
+		v    int32
+		want []byte
+	}{
+		{0, []byte{0}},
+		{-1, []byte{1}},
+		{1, []byte{2}},
+		{-64, []byte{0x7f}},
+		{math.MaxInt32, []byte{0xfe, 0xff, 0xff, 0xff, 0x0f}},
+		{math.MinInt32, []byte{0xff, 0xff, 0xff, 0xff, 0x0f}},
+	} {
+		var b Builder
+		b.AddZigzag32(test.v)
+		if err := builderBytesEq(&b, test.want...); err != nil {
+			t.Errorf("AddZigzag32(%d): %v", test.v, err)
+		}
+
+		s := NewString(b.BytesOrPanic())
+		var v int32
+		if !s.ReadZigzag32(&v) || v != test.v || !s.Empty() {
+			t.Errorf("ReadZigzag32() = %d, want %d", v, test.v)
+		}
+	}
+
+	s := NewString([]byte{0x80, 0x80, 0x80, 0x80, 0x10})
+	var v int32
+	if s.ReadZigzag32(&v) {
+		t.Error("ReadZigzag32() = true for 33-bit value, want false")
+	}
+}
+
+func TestZigzag64(t *testing.T) {
+	for _, test := range []struct {
+		v    int64
+		want []byte
+	}{
+		{0, []byte{0}},
+		{-1, []byte{1}},
+		{1, []byte{2}},
+		{math.MinInt32, []byte{0xff, 0xff, 0xff, 0xff, 0x0f}},
+		{math.MaxInt64, []byte{0xfe, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01}},
+		{math.MinInt64, []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01}},
+	} {
+		var b Builder
+		b.AddZigzag64(test.v)
+		if err := builderBytesEq(&b, test.want...); err != nil {
+			t.Errorf("AddZigzag64(%d): %v", test.v, err)
+		}
+
+		s := NewString(b.BytesOrPanic())
+		var v int64
+		if !s.ReadZigzag64(&v) || v != test.v || !s.Empty() {
+			t.Errorf("ReadZigzag64() = %d, want %d", v, test.v)
+		}
+	}
+}
@@ -0,0 +1,24 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package littlebyte
+
+// AddRIFFChunk adds a RIFF chunk, as used by WAV and AVI files: the 4-byte
+// chunk ID, the little-endian, 32-bit size of the payload, and the payload
+// written by f. If the payload has an odd length, it is followed by a zero pad
+// byte, which is not included in the size.
+func (b *Builder) AddRIFFChunk(id [4]byte, f BuilderContinuation) {
+	b.AddBytes(id[:])
+	if b.err != nil {
+		return
+	}
+	start := b.Len()
+	b.AddUint32LengthPrefixed(f)
+	if b.err != nil {
+		return
+	}
+	if (b.Len()-start-4)%2 != 0 {
+		b.AddUint8(0)
+	}
+}
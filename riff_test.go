@@ -0,0 +1,27 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package littlebyte
+
+import "testing"
+
+func TestAddRIFFChunk(t *testing.T) {
+	var b Builder
+	b.AddRIFFChunk([4]byte{'R', 'I', 'F', 'F'}, func(b *Builder) {
+		b.AddBytes([]byte("WAVE"))
+		b.AddRIFFChunk([4]byte{'d', 'a', 't', 'a'}, func(b *Builder) {
+			b.AddBytes([]byte{1, 2, 3})
+		})
+	})
+	b.AddRIFFChunk([4]byte{'L', 'I', 'S', 'T'}, func(b *Builder) {})
+	want := []byte{
+		'R', 'I', 'F', 'F', 16, 0, 0, 0,
+		'W', 'A', 'V', 'E',
+		'd', 'a', 't', 'a', 3, 0, 0, 0, 1, 2, 3, 0,
+		'L', 'I', 'S', 'T', 0, 0, 0, 0,
+	}
+	if err := builderBytesEq(&b, want...); err != nil {
+		t.Error(err)
+	}
+}
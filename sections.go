@@ -0,0 +1,74 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package littlebyte
+
+import "net"
+
+// SetTrackSections sets whether the Builder records the positions of the
+// bytes added to it with AddBytes, so that Sections can split its output
+// around them. Children do not inherit the setting, so only bytes added
+// directly to b are tracked.
+func (b *Builder) SetTrackSections(track bool) {
+	b.trackSections = track
+}
+
+// The sections field of a Builder holds the start and end positions in its
+// buffer of each sequence of bytes added with AddBytes, in pairs.
+
+// trimSections discards the recorded sections that have been unwritten.
+func (b *Builder) trimSections() {
+	n := len(b.sections)
+	for n > 0 && b.sections[n-1] > len(b.result) {
+		n -= 2
+	}
+	b.sections = b.sections[:n]
+}
+
+// discardSections drops the first n bytes of the Builder's buffer from its
+// recorded sections.
+func (b *Builder) discardSections(n int) {
+	v := b.sections[:0]
+	for i := 0; i < len(b.sections); i += 2 {
+		start, end := b.sections[i]-n, b.sections[i+1]-n
+		if end <= 0 {
+			continue
+		}
+		if start < 0 {
+			start = 0
+		}
+		v = append(v, start, end)
+	}
+	b.sections = v
+}
+
+// Sections returns the bytes written to the Builder, like Bytes, but split
+// into separate buffers before and after each sequence of bytes added with
+// AddBytes while SetTrackSections was enabled. This allows
+// large payloads to be identified and passed to a vectored write without
+// being merged with the surrounding framing. The buffers share the Builder's
+// buffer, so they are only valid until it is next modified. Like Bytes, it
+// writes the bytes to any hash set with TeeHash.
+func (b *Builder) Sections() (net.Buffers, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	if b.child != nil {
+		return nil, b.pendingChildError("Sections")
+	}
+	b.feedTee(b.result, len(b.result))
+	var bufs net.Buffers
+	last := b.offset
+	for _, p := range b.sections {
+		if p <= last || p >= len(b.result) {
+			continue
+		}
+		bufs = append(bufs, b.result[last:p])
+		last = p
+	}
+	if last < len(b.result) {
+		bufs = append(bufs, b.result[last:])
+	}
+	return bufs, nil
+}
@@ -0,0 +1,64 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package littlebyte
+
+import (
+	"errors"
+	"net"
+	"reflect"
+	"testing"
+)
+
+func TestSections(t *testing.T) {
+	payload := []byte("payload")
+	var b Builder
+	b.SetTrackSections(true)
+	b.AddUint16(uint16(len(payload)))
+	b.AddBytes(payload)
+	b.AddBytes(nil)
+	b.AddUint8LengthPrefixed(func(c *Builder) {
+		c.AddBytes([]byte{1, 2})
+	})
+	b.AddBytes([]byte{3})
+	got, err := b.Sections()
+	if err != nil {
+		t.Fatalf("Sections() error = %v", err)
+	}
+	want := net.Buffers{{7, 0}, []byte("payload"), {2, 1, 2}, {3}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Sections() = %v, want %v", got, want)
+	}
+
+	// Boundaries of unwritten bytes are discarded.
+	b.Unwrite(1)
+	b.AddUint8(4)
+	got, _ = b.Sections()
+	want = net.Buffers{{7, 0}, []byte("payload"), {2, 1, 2, 4}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Sections() = %v, want %v", got, want)
+	}
+
+	b.SetError(errors.New("error"))
+	if _, err := b.Sections(); err == nil {
+		t.Error("Sections() error = nil, want error")
+	}
+
+	b = Builder{}
+	b.AddBytes(payload)
+	b.AddUint8(1)
+	got, _ = b.Sections()
+	if len(got) != 1 {
+		t.Errorf("Sections() = %v without SetTrackSections, want a single buffer", got)
+	}
+
+	b = Builder{}
+	b.AddUint8(1)
+	b.AddUint8LengthPrefixed(func(c *Builder) {
+		const want = "littlebyte: Sections called with 1 pending children, the outermost at offset 1"
+		if v, err := b.Sections(); err == nil || err.Error() != want || v != nil {
+			t.Errorf("Sections() = %v, %v; want nil, %q", v, err, want)
+		}
+	})
+}
@@ -0,0 +1,57 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package littlebyte
+
+// AddSqliteVarint appends v as an SQLite variable-length integer, as used in
+// the SQLite record format. It takes from 1 to 9 bytes and is big-endian: each
+// of the first 8 bytes holds 7 bits of the value, with the high bit set if
+// another byte follows, and a 9th byte, if present, holds 8 bits.
+func (b *Builder) AddSqliteVarint(v uint64) {
+	var buf [9]byte
+	if v>>56 != 0 {
+		buf[8] = byte(v)
+		v >>= 8
+		for i := 7; i >= 0; i-- {
+			buf[i] = byte(v) | 0x80
+			v >>= 7
+		}
+		b.add(buf[:]...)
+		return
+	}
+	n := 0
+	for t := v; ; t >>= 7 {
+		n++
+		if t>>7 == 0 {
+			break
+		}
+	}
+	for i := n - 1; i >= 0; i-- {
+		buf[i] = byte(v&0x7f) | 0x80
+		v >>= 7
+	}
+	buf[n-1] &= 0x7f
+	b.add(buf[:n]...)
+}
+
+// ReadSqliteVarint decodes an SQLite variable-length integer, as written by
+// AddSqliteVarint, into out and advances over it. It reports whether the read
+// was successful.
+func (s *String) ReadSqliteVarint(out *uint64) bool {
+	var v uint64
+	for i, c := range s.data {
+		if i == 8 {
+			s.data = s.data[9:]
+			*out = v<<8 | uint64(c)
+			return true
+		}
+		v = v<<7 | uint64(c&0x7f)
+		if c&0x80 == 0 {
+			s.data = s.data[i+1:]
+			*out = v
+			return true
+		}
+	}
+	return s.fail("ReadSqliteVarint")
+}
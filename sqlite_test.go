@@ -0,0 +1,51 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package littlebyte
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSqliteVarint(t *testing.T) {
+	tests := []struct {
+		v    uint64
+		want []byte
+	}{
+		{0, []byte{0x00}},
+		{0x7f, []byte{0x7f}},
+		{0x80, []byte{0x81, 0x00}},
+		{0x3fff, []byte{0xff, 0x7f}},
+		{1<<56 - 1, []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f}},
+		{1 << 56, []byte{0x80, 0xc0, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x00}},
+		{1<<64 - 1, []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}},
+	}
+	for _, tt := range tests {
+		var b Builder
+		b.AddSqliteVarint(tt.v)
+		got := b.BytesOrPanic()
+		if !bytes.Equal(got, tt.want) {
+			t.Errorf("AddSqliteVarint(%#x) = %x, want %x", tt.v, got, tt.want)
+			continue
+		}
+		s := NewString(append(got, 0xaa))
+		var v uint64
+		if !s.ReadSqliteVarint(&v) || v != tt.v {
+			t.Errorf("ReadSqliteVarint(%x) = %#x, want %#x", tt.want, v, tt.v)
+		}
+		if s.Len() != 1 {
+			t.Errorf("ReadSqliteVarint(%x) left %d bytes, want 1", tt.want, s.Len())
+		}
+	}
+
+	var v uint64
+	s := NewString([]byte{0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80})
+	if s.ReadSqliteVarint(&v) {
+		t.Error("ReadSqliteVarint() = true on truncated input, want false")
+	}
+	if s.Len() != 8 {
+		t.Errorf("ReadSqliteVarint() advanced on failure")
+	}
+}
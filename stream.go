@@ -0,0 +1,90 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package littlebyte
+
+import "io"
+
+// stream is the state shared by a streaming Builder and its children.
+type stream struct {
+	w       io.Writer
+	root    *Builder
+	flushed int
+}
+
+// NewStreamingBuilder creates a Builder whose output is written to w. Bytes
+// are buffered until Flush is called, which writes everything that precedes
+// the outermost pending length-prefixed value and drops it from the buffer.
+// Only the currently open length-prefixed values need to be held in memory,
+// since their prefixes cannot be written until they are complete.
+//
+// Bytes returns only the bytes that have not yet been flushed. Flushed bytes
+// cannot be removed with Unwrite or patched through Reserve.
+func NewStreamingBuilder(w io.Writer) *Builder {
+	b := new(Builder)
+	b.stream = &stream{w: w, root: b}
+	return b
+}
+
+// flushedLen returns the number of bytes flushed from the Builder's buffer.
+func (b *Builder) flushedLen() int {
+	if b.stream == nil {
+		return 0
+	}
+	return b.stream.flushed
+}
+
+// Flush writes the finalized bytes of a streaming Builder to its writer. It
+// may be called from within a BuilderContinuation, in which case the bytes
+// before the outermost pending length prefix are written. If the write fails,
+// the error is recorded in the Builder and returned. The written bytes are
+// also written to any hash set with TeeHash. Flush panics if b was not
+// created by NewStreamingBuilder.
+func (b *Builder) Flush() error {
+	if b.stream == nil || b.stream.root != b {
+		panic("littlebyte: Flush called on a non-streaming Builder")
+	}
+	if b.err != nil {
+		return b.err
+	}
+	if b.child == nil {
+		b.feedTee(b.result, len(b.result))
+		if _, err := b.stream.w.Write(b.result); err != nil {
+			b.err = err
+			return err
+		}
+		b.stream.flushed += len(b.result)
+		b.result = b.result[:0]
+		b.sections = b.sections[:0]
+		b.children = b.children[:0]
+		b.teed = 0
+		return nil
+	}
+
+	// The pending children share a buffer, which is most up to date in the
+	// innermost one. Everything before the outermost child's prefix is final.
+	n := b.child.offset
+	last := b
+	for last.child != nil {
+		last = last.child
+	}
+	b.feedTee(last.result, n)
+	if _, err := b.stream.w.Write(last.result[:n]); err != nil {
+		b.err = err
+		return err
+	}
+	b.stream.flushed += n
+	b.result = b.result[n:]
+	b.children = b.children[:0]
+	b.teed = 0
+	b.discardSections(n)
+	for c := b.child; c != nil; c = c.child {
+		c.result = c.result[n:]
+		c.offset -= n
+		for j := range c.children {
+			c.children[j] -= n
+		}
+	}
+	return nil
+}
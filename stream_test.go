@@ -0,0 +1,112 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package littlebyte
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestStreamingBuilder(t *testing.T) {
+	var w bytes.Buffer
+	b := NewStreamingBuilder(&w)
+	b.AddUint16(0x0201)
+	if err := b.Flush(); err != nil {
+		t.Fatalf("Flush() = %v, want nil", err)
+	}
+	if got, want := w.Bytes(), []byte{1, 2}; !bytes.Equal(got, want) {
+		t.Errorf("written = %v, want %v", got, want)
+	}
+	b.AddUint8(3)
+	b.AddUint8LengthPrefixed(func(c *Builder) {
+		c.AddUint8(4)
+		c.AddUint8LengthPrefixed(func(c *Builder) {
+			c.AddUint8(5)
+			if err := b.Flush(); err != nil {
+				t.Fatalf("Flush() = %v, want nil", err)
+			}
+			c.AddUint8(6)
+		})
+		c.AddUint8(7)
+	})
+	if got, want := w.Bytes(), []byte{1, 2, 3}; !bytes.Equal(got, want) {
+		t.Errorf("written = %v, want %v", got, want)
+	}
+	if got, want := b.Len(), 9; got != want {
+		t.Errorf("Len() = %d, want %d", got, want)
+	}
+	if err := b.Flush(); err != nil {
+		t.Fatalf("Flush() = %v, want nil", err)
+	}
+	if got, want := w.Bytes(), []byte{1, 2, 3, 5, 4, 2, 5, 6, 7}; !bytes.Equal(got, want) {
+		t.Errorf("written = %v, want %v", got, want)
+	}
+	if got := b.BytesOrPanic(); len(got) != 0 {
+		t.Errorf("BytesOrPanic() = %v, want []", got)
+	}
+}
+
+func TestStreamingBuilderReserve(t *testing.T) {
+	var w bytes.Buffer
+	b := NewStreamingBuilder(&w)
+	b.AddUint8(1)
+	b.Flush()
+	patch := b.Reserve(1)
+	b.AddUint8(3)
+	patch([]byte{2})
+	b.Flush()
+	if got, want := w.Bytes(), []byte{1, 2, 3}; !bytes.Equal(got, want) {
+		t.Errorf("written = %v, want %v", got, want)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("recover() = nil, want error; patch() did not panic")
+		}
+	}()
+	patch([]byte{2})
+}
+
+func TestStreamingBuilderUnwriteFlushed(t *testing.T) {
+	b := NewStreamingBuilder(new(bytes.Buffer))
+	b.AddUint16(1)
+	b.Flush()
+	b.AddUint8(2)
+	b.Unwrite(1)
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("recover() = nil, want error; Unwrite() did not panic")
+		}
+	}()
+	b.Unwrite(1)
+}
+
+type errWriter struct{ err error }
+
+func (w errWriter) Write(p []byte) (int, error) { return 0, w.err }
+
+func TestStreamingBuilderWriteError(t *testing.T) {
+	werr := errors.New("write failed")
+	b := NewStreamingBuilder(errWriter{werr})
+	b.AddUint8(1)
+	if err := b.Flush(); err != werr {
+		t.Errorf("Flush() = %v, want %v", err, werr)
+	}
+	if _, err := b.Bytes(); err != werr {
+		t.Errorf("Bytes() error = %v, want %v", err, werr)
+	}
+}
+
+func TestFlushNonStreaming(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("recover() = nil, want error; Flush() did not panic")
+		}
+	}()
+	var b Builder
+	b.Flush()
+}
@@ -16,24 +16,133 @@
 // started.
 package littlebyte
 
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+)
+
+const maxInt = int(^uint(0) >> 1)
+
 // String represents a string of bytes. It provides methods for parsing
 // fixed-length and length-prefixed values from it.
-type String []byte
+//
+// A String also records the first of its reads to fail, so that a sequence of
+// reads can be checked once and the failure described by Reason.
+type String struct {
+	data   []byte
+	base   []byte
+	reason string
+}
+
+// NewString creates a String that reads from the given bytes.
+func NewString(data []byte) String {
+	return String{data: data, base: data}
+}
+
+// Bytes returns the bytes that remain to be read from the String.
+func (s String) Bytes() []byte {
+	return s.data
+}
+
+// Len returns the number of bytes that remain to be read from the String.
+func (s String) Len() int {
+	return len(s.data)
+}
+
+// Reason returns a description of the first read on the String that failed,
+// or the empty string if none has.
+func (s String) Reason() string {
+	return s.reason
+}
+
+// fail records that the named read failed, unless an earlier read has already
+// failed, and returns false.
+func (s *String) fail(name string) bool {
+	if s.reason == "" {
+		s.reason = fmt.Sprintf("littlebyte: %s failed with %d bytes remaining", name, len(s.data))
+	}
+	return false
+}
 
 // read advances a String by n bytes and returns them. If less than n bytes
 // remain, it returns nil.
 func (s *String) read(n int) []byte {
-	if len(*s) < n {
+	if n < 0 || len(s.data) < n {
 		return nil
 	}
-	v := (*s)[:n]
-	*s = (*s)[n:]
+	v := s.data[:n]
+	s.data = s.data[n:]
 	return v
 }
 
 // Skip advances the String by n byte and reports whether it was successful.
 func (s *String) Skip(n int) bool {
-	return s.read(n) != nil
+	return s.read(n) != nil || s.fail("Skip")
+}
+
+// Truncate shrinks the String to its first n bytes, discarding the rest, so
+// that subsequent reads cannot reach them. It reports whether it was
+// successful, which requires that n is not greater than the String's length.
+func (s *String) Truncate(n int) bool {
+	if n < 0 || n > len(s.data) {
+		return s.fail("Truncate")
+	}
+	s.data = s.data[:n]
+	return true
+}
+
+// Split returns the next n bytes as a String and advances over them. ok
+// reports whether it was successful; if not, s is unchanged.
+func (s *String) Split(n int) (head String, ok bool) {
+	v := s.read(n)
+	if v == nil {
+		return String{}, s.fail("Split")
+	}
+	return NewString(v), true
+}
+
+// Unread moves s back by n bytes, so that they are read again. It reports
+// whether it was successful, which requires that the bytes were previously
+// consumed from s. A String cannot be moved back past the start of the bytes
+// it was created with, so the content read by, for example,
+// ReadUint8LengthPrefixed cannot be moved back into its length prefix.
+func (s *String) Unread(n int) bool {
+	off := cap(s.base) - cap(s.data)
+	if n < 0 || n > off {
+		return s.fail("Unread")
+	}
+	s.data = s.base[off-n : off+len(s.data)]
+	return true
+}
+
+// ConsumedSince returns the number of bytes consumed from s since start, which
+// is a copy of s saved at an earlier point in parsing. It panics if s was not
+// derived from start.
+func (s String) ConsumedSince(start String) int {
+	n := cap(start.data) - cap(s.data)
+	if n < 0 || n > len(start.data) || (cap(s.data) > 0 && &start.data[:cap(start.data)][n] != &s.data[:cap(s.data)][0]) {
+		panic("littlebyte: String was not derived from start")
+	}
+	return n
+}
+
+// ConsumedExactly reports whether exactly n bytes have been consumed from s
+// since start, which is a copy of s saved at an earlier point in parsing. It
+// is useful for checking that a message was fully parsed according to its
+// declared length, catching both under- and over-reads.
+func (s String) ConsumedExactly(start String, n int) bool {
+	return s.ConsumedSince(start) == n
+}
+
+// AlignTo advances the String over the padding needed to bring its offset, as
+// reported by BytesRead, to a multiple of n. It reports whether it was
+// successful. n must be a power of two.
+func (s *String) AlignTo(n int) bool {
+	if n <= 0 || n&(n-1) != 0 {
+		panic("littlebyte: alignment is not a power of two")
+	}
+	return s.read(-s.BytesRead()&(n-1)) != nil || s.fail("AlignTo")
 }
 
 // ReadUint8 decodes an 8-bit value into out and advances over it.
@@ -41,29 +150,51 @@ func (s *String) Skip(n int) bool {
 func (s *String) ReadUint8(out *uint8) bool {
 	v := s.read(1)
 	if v == nil {
-		return false
+		return s.fail("ReadUint8")
 	}
 	*out = uint8(v[0])
 	return true
 }
 
+// ReadBool decodes an 8-bit boolean into out and advances over it. Any nonzero
+// value is true. It reports whether the read was successful.
+func (s *String) ReadBool(out *bool) bool {
+	v := s.read(1)
+	if v == nil {
+		return s.fail("ReadBool")
+	}
+	*out = v[0] != 0
+	return true
+}
+
+// ReadBoolStrict is like ReadBool, but the read fails, without advancing, if
+// the value is not 0 or 1.
+func (s *String) ReadBoolStrict(out *bool) bool {
+	if len(s.data) == 0 || s.data[0] > 1 {
+		return s.fail("ReadBoolStrict")
+	}
+	*out = s.read(1)[0] == 1
+	return true
+}
+
 // ReadUint16 decodes a little-endian, 16-bit value into out and advances over it.
 // It reports whether the read was successful.
 func (s *String) ReadUint16(out *uint16) bool {
 	v := s.read(2)
 	if v == nil {
-		return false
+		return s.fail("ReadUint16")
 	}
 	*out = uint16(v[0]) | uint16(v[1])<<8
 	return true
 }
 
 // ReadUint24 decodes a little-endian, 24-bit value into out and advances over it.
-// It reports whether the read was successful.
+// It reports whether the read was successful. On success, out is overwritten
+// entirely, so its top byte is always zero and it is less than 1<<24.
 func (s *String) ReadUint24(out *uint32) bool {
 	v := s.read(3)
 	if v == nil {
-		return false
+		return s.fail("ReadUint24")
 	}
 	*out = uint32(v[0]) | uint32(v[1])<<8 | uint32(v[2])<<16
 	return true
@@ -74,12 +205,71 @@ func (s *String) ReadUint24(out *uint32) bool {
 func (s *String) ReadUint32(out *uint32) bool {
 	v := s.read(4)
 	if v == nil {
-		return false
+		return s.fail("ReadUint32")
 	}
 	*out = uint32(v[0]) | uint32(v[1])<<8 | uint32(v[2])<<16 | uint32(v[3])<<24
 	return true
 }
 
+// ReadUint decodes a little-endian value of width bytes into out and advances
+// over it. It reports whether the read was successful. It panics if width is
+// not between 1 and 8.
+func (s *String) ReadUint(out *uint64, width int) bool {
+	if width < 1 || width > 8 {
+		panic("littlebyte: invalid integer width")
+	}
+	v := s.read(width)
+	if v == nil {
+		return s.fail("ReadUint")
+	}
+	var result uint64
+	for i := width - 1; i >= 0; i-- {
+		result = result<<8 | uint64(v[i])
+	}
+	*out = result
+	return true
+}
+
+// ReadUint8Array reads len(out) 8-bit values into out and advances over them.
+// It reports whether the read was successful. If there are not enough bytes,
+// out is left unmodified.
+func (s *String) ReadUint8Array(out []uint8) bool {
+	v := s.read(len(out))
+	if v == nil {
+		return s.fail("ReadUint8Array")
+	}
+	copy(out, v)
+	return true
+}
+
+// ReadUint16Array decodes len(out) little-endian, 16-bit values into out and
+// advances over them. It reports whether the read was successful. If there are
+// not enough bytes, out is left unmodified.
+func (s *String) ReadUint16Array(out []uint16) bool {
+	if len(out) > len(s.data)/2 {
+		return s.fail("ReadUint16Array")
+	}
+	v := s.read(len(out) * 2)
+	for i := range out {
+		out[i] = uint16(v[2*i]) | uint16(v[2*i+1])<<8
+	}
+	return true
+}
+
+// ReadUint32Array decodes len(out) little-endian, 32-bit values into out and
+// advances over them. It reports whether the read was successful. If there are
+// not enough bytes, out is left unmodified.
+func (s *String) ReadUint32Array(out []uint32) bool {
+	if len(out) > len(s.data)/4 {
+		return s.fail("ReadUint32Array")
+	}
+	v := s.read(len(out) * 4)
+	for i := range out {
+		out[i] = uint32(v[4*i]) | uint32(v[4*i+1])<<8 | uint32(v[4*i+2])<<16 | uint32(v[4*i+3])<<24
+	}
+	return true
+}
+
 func (s *String) readUnsigned(out *uint32, length int) bool {
 	v := s.read(length)
 	if v == nil {
@@ -111,53 +301,473 @@ func (s *String) readLengthPrefixed(lenLen int, outChild *String) bool {
 	if v == nil {
 		return false
 	}
-	*outChild = v
+	*outChild = NewString(v)
+	return true
+}
+
+// readLengthPrefixedBE is like readLengthPrefixed, but the length prefix is
+// big-endian.
+func (s *String) readLengthPrefixedBE(lenLen int, outChild *String) bool {
+	lenBytes := s.read(lenLen)
+	if lenBytes == nil {
+		return false
+	}
+	var length uint32
+	for _, b := range lenBytes {
+		length = length<<8 | uint32(b)
+	}
+	if int(length) < 0 {
+		return false
+	}
+	v := s.read(int(length))
+	if v == nil {
+		return false
+	}
+	*outChild = NewString(v)
 	return true
 }
 
 // ReadUint8LengthPrefixed reads the content of an 8-bit length-prefixed value
 // into out and advances over it. It reports whether the read was successful.
 func (s *String) ReadUint8LengthPrefixed(out *String) bool {
-	return s.readLengthPrefixed(1, out)
+	return s.readLengthPrefixed(1, out) || s.fail("ReadUint8LengthPrefixed")
 }
 
 // ReadUint16LengthPrefixed reads the content of a little-endian, 16-bit
 // length-prefixed value into out and advances over it. It reports whether the
 // read was successful.
 func (s *String) ReadUint16LengthPrefixed(out *String) bool {
-	return s.readLengthPrefixed(2, out)
+	return s.readLengthPrefixed(2, out) || s.fail("ReadUint16LengthPrefixed")
+}
+
+// ReadUint16LengthPrefixedRaw reads a little-endian, 16-bit length-prefixed
+// value and advances over it, setting outBytes to its content and outString to
+// a String over the same content. This allows the content to be both parsed
+// and, for example, hashed for signature verification, without reading it
+// twice. outBytes aliases the String's data, like the result of ReadBytes, and
+// is not affected by reads from outString. It reports whether the read was
+// successful.
+func (s *String) ReadUint16LengthPrefixedRaw(outBytes *[]byte, outString *String) bool {
+	var v String
+	if !s.readLengthPrefixed(2, &v) {
+		return s.fail("ReadUint16LengthPrefixedRaw")
+	}
+	*outBytes = v.Bytes()
+	*outString = v
+	return true
+}
+
+// ReadUint16LengthPrefixedExact reads a little-endian, 16-bit length-prefixed
+// value and calls fn to parse its content. It reports whether the read was
+// successful, which requires that fn returns true and consumes the entire
+// content. If not, s is unchanged.
+func (s *String) ReadUint16LengthPrefixedExact(fn func(*String) bool) bool {
+	t := *s
+	var v String
+	if !t.readLengthPrefixed(2, &v) || !fn(&v) || !v.Empty() {
+		return s.fail("ReadUint16LengthPrefixedExact")
+	}
+	*s = t
+	return true
 }
 
 // ReadUint24LengthPrefixed reads the content of a little-endian, 24-bit
 // length-prefixed value into out and advances over it. It reports whether
 // the read was successful.
 func (s *String) ReadUint24LengthPrefixed(out *String) bool {
+	return s.readLengthPrefixed(3, out) || s.fail("ReadUint24LengthPrefixed")
+}
+
+// ReadUint32LengthPrefixedLimit reads the content of a little-endian, 32-bit
+// length-prefixed value into out and advances over it. It fails without
+// examining the content if the declared length exceeds max, which makes it
+// suitable for parsing untrusted input. It reports whether the read was
+// successful.
+func (s *String) ReadUint32LengthPrefixedLimit(out *String, max uint32) bool {
+	var length uint32
+	v := *s
+	if !v.readUnsigned(&length, 4) || length > max || !s.readLengthPrefixed(4, out) {
+		return s.fail("ReadUint32LengthPrefixedLimit")
+	}
+	return true
+}
+
+// readEach reads lenLen-byte length-prefixed elements until s is empty,
+// calling fn with the content of each.
+func (s *String) readEach(lenLen int, name string, fn func(String) bool) bool {
+	t := *s
+	for !t.Empty() {
+		var v String
+		if !t.readLengthPrefixed(lenLen, &v) || !fn(v) {
+			return s.fail(name)
+		}
+	}
+	*s = t
+	return true
+}
+
+// ReadEachUint8LengthPrefixed reads a list of 8-bit length-prefixed elements
+// that makes up the rest of the String, calling fn with the content of each in
+// turn. It reports whether the read was successful, which requires that every
+// element is well-formed and that fn returns true for each. If so, the String
+// is left empty; otherwise, it is unchanged, although fn may have been called
+// for some elements.
+//
+// s is typically itself the content of a length-prefixed list:
+//
+//	var list String
+//	ok := input.ReadUint16LengthPrefixed(&list) &&
+//		list.ReadEachUint8LengthPrefixed(func(v String) bool {
+//			names = append(names, string(v.Bytes()))
+//			return true
+//		})
+func (s *String) ReadEachUint8LengthPrefixed(fn func(String) bool) bool {
+	return s.readEach(1, "ReadEachUint8LengthPrefixed", fn)
+}
+
+// ReadEachUint16LengthPrefixed is like ReadEachUint8LengthPrefixed, but for
+// little-endian, 16-bit length-prefixed elements.
+func (s *String) ReadEachUint16LengthPrefixed(fn func(String) bool) bool {
+	return s.readEach(2, "ReadEachUint16LengthPrefixed", fn)
+}
+
+// ReadEachUint24LengthPrefixed is like ReadEachUint8LengthPrefixed, but for
+// little-endian, 24-bit length-prefixed elements.
+func (s *String) ReadEachUint24LengthPrefixed(fn func(String) bool) bool {
+	return s.readEach(3, "ReadEachUint24LengthPrefixed", fn)
+}
+
+// ReadEachUint32LengthPrefixed is like ReadEachUint8LengthPrefixed, but for
+// little-endian, 32-bit length-prefixed elements.
+func (s *String) ReadEachUint32LengthPrefixed(fn func(String) bool) bool {
+	return s.readEach(4, "ReadEachUint32LengthPrefixed", fn)
+}
+
+// ReadUint16LengthPrefixedBE reads the content of a big-endian, 16-bit
+// length-prefixed value into out and advances over it. It reports whether the
+// read was successful. This is for formats that frame little-endian content
+// with big-endian lengths.
+func (s *String) ReadUint16LengthPrefixedBE(out *String) bool {
+	return s.readLengthPrefixedBE(2, out) || s.fail("ReadUint16LengthPrefixedBE")
+}
+
+// ReadUint24LengthPrefixedBE reads the content of a big-endian, 24-bit
+// length-prefixed value into out and advances over it. It reports whether the
+// read was successful.
+func (s *String) ReadUint24LengthPrefixedBE(out *String) bool {
+	return s.readLengthPrefixedBE(3, out) || s.fail("ReadUint24LengthPrefixedBE")
+}
+
+// ReadUint32LengthPrefixedBE reads the content of a big-endian, 32-bit
+// length-prefixed value into out and advances over it. It reports whether the
+// read was successful.
+func (s *String) ReadUint32LengthPrefixedBE(out *String) bool {
+	return s.readLengthPrefixedBE(4, out) || s.fail("ReadUint32LengthPrefixedBE")
+}
+
+// PeekUint8LengthPrefixed reads the content of an 8-bit length-prefixed value
+// into out without advancing over it. It reports whether the read was
+// successful.
+func (s String) PeekUint8LengthPrefixed(out *String) bool {
+	return s.readLengthPrefixed(1, out)
+}
+
+// PeekUint16LengthPrefixed reads the content of a little-endian, 16-bit
+// length-prefixed value into out without advancing over it. It reports whether
+// the read was successful.
+func (s String) PeekUint16LengthPrefixed(out *String) bool {
+	return s.readLengthPrefixed(2, out)
+}
+
+// PeekUint24LengthPrefixed reads the content of a little-endian, 24-bit
+// length-prefixed value into out without advancing over it. It reports whether
+// the read was successful.
+func (s String) PeekUint24LengthPrefixed(out *String) bool {
 	return s.readLengthPrefixed(3, out)
 }
 
+// PeekUint32LengthPrefixed reads the content of a little-endian, 32-bit
+// length-prefixed value into out without advancing over it. It reports whether
+// the read was successful.
+func (s String) PeekUint32LengthPrefixed(out *String) bool {
+	return s.readLengthPrefixed(4, out)
+}
+
+// ReadUint16ElementPrefixed reads the content of a value prefixed with the
+// little-endian, 16-bit count of its elemSize-byte elements into out and
+// advances over it. It reports whether the read was successful.
+func (s *String) ReadUint16ElementPrefixed(out *String, elemSize int) bool {
+	if elemSize <= 0 {
+		panic("littlebyte: invalid element size")
+	}
+	var count uint32
+	if !s.readUnsigned(&count, 2) {
+		return s.fail("ReadUint16ElementPrefixed")
+	}
+	if count != 0 && elemSize > maxInt/int(count) {
+		return s.fail("ReadUint16ElementPrefixed")
+	}
+	v := s.read(int(count) * elemSize)
+	if v == nil {
+		return s.fail("ReadUint16ElementPrefixed")
+	}
+	*out = NewString(v)
+	return true
+}
+
+// ReadUint16LengthPrefixedFill reads little-endian, 16-bit length-prefixed
+// values from the next totalBytes bytes, until they are exhausted, appends
+// their contents to out and advances over them. It reports whether the read
+// was successful. The read fails if a value extends beyond totalBytes; if so,
+// s and out are unchanged.
+func (s *String) ReadUint16LengthPrefixedFill(out *[]String, totalBytes int) bool {
+	t := *s
+	v := t.read(totalBytes)
+	if v == nil {
+		return s.fail("ReadUint16LengthPrefixedFill")
+	}
+	region := NewString(v)
+	values := *out
+	for !region.Empty() {
+		var v String
+		if !region.readLengthPrefixed(2, &v) {
+			return s.fail("ReadUint16LengthPrefixedFill")
+		}
+		values = append(values, v)
+	}
+	*out = values
+	*s = t
+	return true
+}
+
+// ReadUint8LengthPrefixedUint64 decodes an 8-bit length-prefixed,
+// little-endian integer into out and advances over it. It reports whether the
+// read was successful. The read fails if the integer is longer than 8 bytes.
+func (s *String) ReadUint8LengthPrefixedUint64(out *uint64) bool {
+	var v String
+	if !s.readLengthPrefixed(1, &v) || len(v.data) > 8 {
+		return s.fail("ReadUint8LengthPrefixedUint64")
+	}
+	var result uint64
+	for i := len(v.data) - 1; i >= 0; i-- {
+		result = result<<8 | uint64(v.data[i])
+	}
+	*out = result
+	return true
+}
+
+// ReadUint16LengthPrefixedInto copies the content of a little-endian, 16-bit
+// length-prefixed value into dst and advances over it. It returns the number
+// of bytes copied and reports whether the read was successful. The read fails,
+// without advancing, if the content is longer than dst.
+func (s *String) ReadUint16LengthPrefixedInto(dst []byte) (n int, ok bool) {
+	t := *s
+	var child String
+	if !t.readLengthPrefixed(2, &child) || len(child.data) > len(dst) {
+		return 0, s.fail("ReadUint16LengthPrefixedInto")
+	}
+	*s = t
+	return copy(dst, child.data), true
+}
+
 // ReadBytes reads n bytes into out and advances over them. It reports
 // whether the read was successful.
+//
+// out is set to a slice of the String's bytes rather than a copy, so it is
+// only valid for as long as the underlying buffer is not modified or reused.
+// Use ReadBytesCopy to retain the bytes beyond that.
 func (s *String) ReadBytes(out *[]byte, n int) bool {
 	v := s.read(n)
 	if v == nil {
-		return false
+		return s.fail("ReadBytes")
 	}
 	*out = v
 	return true
 }
 
+// ReadFixedString reads a field of width bytes, removes any trailing copies
+// of trimPad, and stores the result in out. It advances over the whole field
+// and reports whether the read was successful.
+func (s *String) ReadFixedString(out *string, width int, trimPad byte) bool {
+	v := s.read(width)
+	if v == nil && width != 0 {
+		return s.fail("ReadFixedString")
+	}
+	n := len(v)
+	for n > 0 && v[n-1] == trimPad {
+		n--
+	}
+	*out = string(v[:n])
+	return true
+}
+
+// ReadCStringMax reads a NUL-terminated string into out, without the NUL, and
+// advances over it and the NUL. Only the first max bytes are searched for the
+// NUL, so the string is at most max-1 bytes long, and a string that is not
+// terminated within them is not scanned to the end of s. It reports whether
+// the read was successful; if not, s is unchanged.
+func (s *String) ReadCStringMax(out *string, max int) bool {
+	v := s.data
+	if max < len(v) {
+		if max < 0 {
+			max = 0
+		}
+		v = v[:max]
+	}
+	n := bytes.IndexByte(v, 0)
+	if n < 0 {
+		return s.fail("ReadCStringMax")
+	}
+	*out = string(s.read(n + 1)[:n])
+	return true
+}
+
+// ReadBytesCopy reads n bytes into a newly allocated slice, which is stored
+// in out, and advances over them. It reports whether the read was successful.
+func (s *String) ReadBytesCopy(out *[]byte, n int) bool {
+	v := s.read(n)
+	if v == nil {
+		return s.fail("ReadBytesCopy")
+	}
+	*out = append([]byte(nil), v...)
+	return true
+}
+
+// ReadRemaining reads all of the remaining bytes into out and advances over
+// them, leaving the String empty. It always succeeds.
+func (s *String) ReadRemaining(out *[]byte) {
+	*out = s.data
+	s.data = s.data[len(s.data):]
+}
+
+// Rest returns the remaining bytes without advancing over them. It is
+// equivalent to Bytes.
+func (s String) Rest() []byte {
+	return s.data
+}
+
 // CopyBytes copies len(out) bytes into out and advances over them. It reports
-// whether the copy operation was successful
+// whether the copy operation was successful. If fewer than len(out) bytes
+// remain, out is left unmodified.
+//
+// Unlike ReadBytes, CopyBytes does not allocate or alias the String's bytes,
+// which makes it suitable for reading fixed-size fields such as MAC addresses
+// into an existing array:
+//
+//	var mac [6]byte
+//	ok := s.CopyBytes(mac[:])
+//
+// GUIDs stored in Microsoft's mixed-endian layout should be read with ReadGUID
+// instead.
 func (s *String) CopyBytes(out []byte) bool {
 	n := len(out)
 	v := s.read(n)
 	if v == nil {
-		return false
+		return s.fail("CopyBytes")
 	}
 	return copy(out, v) == n
 }
 
+// CopyTo copies as many of the remaining bytes as fit into dst and advances
+// over them. It returns the number of bytes copied, which is the minimum of
+// the String's length and len(dst). Like io.Reader's Read method, it allows
+// the String to be drained into a reusable buffer without allocating.
+func (s *String) CopyTo(dst []byte) (n int) {
+	n = copy(dst, s.data)
+	s.data = s.data[n:]
+	return n
+}
+
 // Empty reports whether the string does not contain any bytes.
 func (s String) Empty() bool {
-	return len(s) == 0
+	return len(s.data) == 0
+}
+
+// MustEmpty returns an error reporting the number of bytes remaining if the
+// string is not empty, and nil otherwise. It is intended for checking that a
+// parser has consumed its entire input.
+func (s String) MustEmpty() error {
+	if len(s.data) != 0 {
+		return fmt.Errorf("littlebyte: %d unexpected trailing bytes", len(s.data))
+	}
+	return nil
+}
+
+// BytesRead returns the number of bytes that have been consumed from s since
+// it was created; that is, the offset of its read position within the bytes
+// passed to NewString or, for the content of a length-prefixed value, within
+// that content. It is useful for reporting the position of a parse error. Use
+// ConsumedSince to measure from another point.
+func (s String) BytesRead() int {
+	return cap(s.base) - cap(s.data)
+}
+
+// ReadIf calls f to read an optional value from s if cond is true. It reports
+// whether the read was successful, which is always the case if cond is false.
+// This is useful for reading a field whose presence depends on a previously
+// read value, e.g.
+//
+//	s.ReadUint8(&flags) && s.ReadIf(flags&4 != 0, func(s *String) bool {
+//		return s.ReadUint32(&extra)
+//	})
+func (s *String) ReadIf(cond bool, f func(s *String) bool) bool {
+	if !cond {
+		return true
+	}
+	return f(s)
+}
+
+// Hex returns the remaining bytes of the String encoded as hexadecimal. It is
+// intended for debugging.
+func (s String) Hex() string {
+	return hex.EncodeToString(s.data)
+}
+
+// HexDump returns a hex dump of the remaining bytes of the String, in the
+// format of `hexdump -C`. It is intended for debugging.
+func (s String) HexDump() string {
+	return hex.Dump(s.data)
+}
+
+// ReadHexString reads hexLen ASCII hexadecimal characters, decodes them into
+// a newly allocated slice, which is stored in out, and advances over them. It
+// reports whether the read was successful; if not, s is unchanged. It panics
+// if hexLen is odd.
+func (s *String) ReadHexString(out *[]byte, hexLen int) bool {
+	if hexLen%2 != 0 {
+		panic("littlebyte: odd hex string length")
+	}
+	t := *s
+	v := t.read(hexLen)
+	if v == nil {
+		return s.fail("ReadHexString")
+	}
+	dst := make([]byte, hexLen/2)
+	if _, err := hex.Decode(dst, v); err != nil {
+		return s.fail("ReadHexString")
+	}
+	*out = dst
+	*s = t
+	return true
+}
+
+// Clone returns a copy of s that can be read independently of s, for example
+// to speculatively parse a value and fall back to another interpretation on
+// failure by continuing with s. The clone shares the underlying bytes with s,
+// which must not be modified, but has its own read position and failure
+// reason.
+func (s String) Clone() String {
+	return s
+}
+
+// ReadOptional reads an 8-bit presence flag, which must be 0 or 1, into
+// present and then, if the flag is 1, calls f to read the value that follows
+// it. It reports whether the read was successful.
+func (s *String) ReadOptional(present *bool, f func(s *String) bool) bool {
+	var flag uint32
+	if !s.readUnsigned(&flag, 1) || flag > 1 {
+		return s.fail("ReadOptional")
+	}
+	*present = flag == 1
+	return !*present || f(s)
 }
@@ -0,0 +1,247 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package littlebyte
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// fieldWidth returns the number of bytes used to encode an integer of type t
+// with the given tag.
+func fieldWidth(t reflect.Type, tag string) (int, error) {
+	switch t.Kind() {
+	case reflect.Int, reflect.Uint, reflect.Uintptr:
+		if tag == "" {
+			return 0, fmt.Errorf("littlebyte: field of type %s needs a width tag", t)
+		}
+	}
+	if tag == "" {
+		return int(t.Size()), nil
+	}
+	var n int
+	switch tag {
+	case "uint8", "int8":
+		n = 1
+	case "uint16", "int16":
+		n = 2
+	case "uint24", "int24":
+		n = 3
+	case "uint32", "int32":
+		n = 4
+	case "uint64", "int64":
+		n = 8
+	default:
+		return 0, fmt.Errorf("littlebyte: invalid tag %q for field of type %s", tag, t)
+	}
+	signedTag := tag[0] == 'i'
+	switch t.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if signedTag {
+			return 0, fmt.Errorf("littlebyte: tag %q requires a signed field, not %s", tag, t)
+		}
+	default:
+		if !signedTag {
+			return 0, fmt.Errorf("littlebyte: tag %q requires an unsigned field, not %s", tag, t)
+		}
+	}
+	if n > int(t.Size()) {
+		return 0, fmt.Errorf("littlebyte: tag %q is too wide for field of type %s", tag, t)
+	}
+	return n, nil
+}
+
+// prefixWidth returns the width in bytes of the length prefix given by tag.
+func prefixWidth(t reflect.Type, tag string) (int, error) {
+	switch tag {
+	case "len8":
+		return 1, nil
+	case "len16":
+		return 2, nil
+	case "len24":
+		return 3, nil
+	case "len32":
+		return 4, nil
+	}
+	return 0, fmt.Errorf("littlebyte: field of type %s needs a length prefix tag", t)
+}
+
+// ReadStruct decodes a little-endian structure into the struct pointed to by
+// ptr and advances over it. It reports whether the read was successful; if
+// not, s and the struct are unchanged.
+//
+// Exported fields are decoded in order, and unexported fields and those
+// tagged `littlebyte:"-"` are skipped. Fields may have the following types:
+//
+//   - Sized integer types, which are encoded using their own width unless
+//     the field is tagged with a narrower width: `littlebyte:"uint8"`,
+//     "uint16", "uint24", "uint32" or "uint64" for an unsigned field, or
+//     "int8", "int16", "int24", "int32" or "int64" for a signed field, which
+//     is sign-extended. Fields of type int, uint and uintptr must have a
+//     width tag.
+//   - Arrays, whose elements are decoded in turn using the field's tag.
+//   - Structs, which are decoded recursively.
+//   - []byte and string, which must be tagged with the width of their
+//     little-endian length prefix: `littlebyte:"len8"`, "len16", "len24" or
+//     "len32". The bytes are copied.
+//
+// ReadStruct is slower than the typed read methods, since it uses reflection.
+// It panics if ptr is not a non-nil pointer to a struct, and the read fails if
+// the struct has a field that cannot be decoded.
+func (s *String) ReadStruct(ptr interface{}) bool {
+	p := reflect.ValueOf(ptr)
+	if p.Kind() != reflect.Ptr || p.IsNil() || p.Elem().Kind() != reflect.Struct {
+		panic("littlebyte: ReadStruct requires a non-nil pointer to a struct")
+	}
+	v := reflect.New(p.Elem().Type()).Elem()
+	t := *s
+	if err := t.readValue(v, ""); err != nil {
+		return s.fail("ReadStruct")
+	}
+	p.Elem().Set(v)
+	*s = t
+	return true
+}
+
+var errShortStruct = errors.New("littlebyte: not enough data for struct")
+
+func (s *String) readValue(v reflect.Value, tag string) error {
+	t := v.Type()
+	switch t.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := fieldWidth(t, tag)
+		if err != nil {
+			return err
+		}
+		var u uint64
+		if !s.ReadUint(&u, n) {
+			return errShortStruct
+		}
+		if v.Kind() >= reflect.Int && v.Kind() <= reflect.Int64 {
+			shift := uint(64 - 8*n)
+			v.SetInt(int64(u<<shift) >> shift)
+		} else {
+			v.SetUint(u)
+		}
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := s.readValue(v.Index(i), tag); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			tag := f.Tag.Get("littlebyte")
+			if f.PkgPath != "" || tag == "-" {
+				continue
+			}
+			if err := s.readValue(v.Field(i), tag); err != nil {
+				return err
+			}
+		}
+	case reflect.String, reflect.Slice:
+		if t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8 {
+			return fmt.Errorf("littlebyte: unsupported field type %s", t)
+		}
+		n, err := prefixWidth(t, tag)
+		if err != nil {
+			return err
+		}
+		var body String
+		if !s.readLengthPrefixed(n, &body) {
+			return errShortStruct
+		}
+		if t.Kind() == reflect.String {
+			v.SetString(string(body.data))
+		} else {
+			v.SetBytes(append([]byte{}, body.data...))
+		}
+	default:
+		return fmt.Errorf("littlebyte: unsupported field type %s", t)
+	}
+	return nil
+}
+
+// AddStruct appends the struct v, or the struct pointed to by v, in the
+// little-endian structure described by ReadStruct. If the struct has a field
+// that cannot be encoded, or an integer field holds a value too large for the
+// width given by its tag, an error is set on the Builder.
+func (b *Builder) AddStruct(v interface{}) {
+	if b.err != nil {
+		return
+	}
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		b.err = fmt.Errorf("littlebyte: AddStruct requires a struct, not %T", v)
+		return
+	}
+	if err := b.addValue(rv, ""); err != nil && b.err == nil {
+		b.err = err
+	}
+}
+
+func (b *Builder) addValue(v reflect.Value, tag string) error {
+	t := v.Type()
+	switch t.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := fieldWidth(t, tag)
+		if err != nil {
+			return err
+		}
+		var u uint64
+		if t.Kind() >= reflect.Int && t.Kind() <= reflect.Int64 {
+			x := v.Int()
+			if shift := uint(64 - 8*n); x<<shift>>shift != x {
+				return fmt.Errorf("littlebyte: value %d overflows %s field", x, tag)
+			}
+			u = uint64(x)
+		} else {
+			u = v.Uint()
+			if n < 8 && u>>uint(8*n) != 0 {
+				return fmt.Errorf("littlebyte: value %d overflows %s field", u, tag)
+			}
+		}
+		b.AddUint(u, n)
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := b.addValue(v.Index(i), tag); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			tag := f.Tag.Get("littlebyte")
+			if f.PkgPath != "" || tag == "-" {
+				continue
+			}
+			if err := b.addValue(v.Field(i), tag); err != nil {
+				return err
+			}
+		}
+	case reflect.String, reflect.Slice:
+		if t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8 {
+			return fmt.Errorf("littlebyte: unsupported field type %s", t)
+		}
+		n, err := prefixWidth(t, tag)
+		if err != nil {
+			return err
+		}
+		b.addLengthPrefixed(n, false, func(child *Builder) {
+			if t.Kind() == reflect.String {
+				child.AddString(v.String())
+			} else {
+				child.AddBytes(v.Bytes())
+			}
+		})
+	default:
+		return fmt.Errorf("littlebyte: unsupported field type %s", t)
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package littlebyte
+
+import (
+	"reflect"
+	"testing"
+)
+
+type testStructPoint struct {
+	X, Y int16
+}
+
+type testStruct struct {
+	Magic   [4]byte
+	Version uint8
+	Size    uint32 `littlebyte:"uint24"`
+	Offset  int32
+	Points  [2]testStructPoint
+	Name    string `littlebyte:"len8"`
+	Data    []byte `littlebyte:"len16"`
+	Count   uint   `littlebyte:"uint16"`
+	Skipped uint64 `littlebyte:"-"`
+	private uint64
+}
+
+var testStructBytes = []byte{
+	'R', 'I', 'F', 'F',
+	2,
+	0x03, 0x02, 0x01,
+	0xfe, 0xff, 0xff, 0xff,
+	1, 0, 0xff, 0xff, 3, 0, 4, 0,
+	2, 'h', 'i',
+	3, 0, 7, 8, 9,
+	0x34, 0x12,
+}
+
+var testStructValue = testStruct{
+	Magic:   [4]byte{'R', 'I', 'F', 'F'},
+	Version: 2,
+	Size:    0x010203,
+	Offset:  -2,
+	Points:  [2]testStructPoint{{1, -1}, {3, 4}},
+	Name:    "hi",
+	Data:    []byte{7, 8, 9},
+	Count:   0x1234,
+}
+
+func TestReadStruct(t *testing.T) {
+	s := NewString(append(testStructBytes, 0xaa))
+	var got testStruct
+	if !s.ReadStruct(&got) {
+		t.Fatal("ReadStruct() = false, want true")
+	}
+	if !reflect.DeepEqual(got, testStructValue) {
+		t.Errorf("ReadStruct() = %+v, want %+v", got, testStructValue)
+	}
+	if s.Len() != 1 {
+		t.Errorf("s.Len() = %d, want 1", s.Len())
+	}
+
+	s = NewString(testStructBytes[:len(testStructBytes)-1])
+	got = testStruct{Version: 9}
+	if s.ReadStruct(&got) {
+		t.Error("ReadStruct() = true on short input, want false")
+	}
+	if got.Version != 9 || s.Len() != len(testStructBytes)-1 {
+		t.Error("ReadStruct() modified its arguments on failure")
+	}
+
+	var bad struct {
+		N int
+	}
+	s = NewString(make([]byte, 8))
+	if s.ReadStruct(&bad) {
+		t.Error("ReadStruct() = true for int field without width tag, want false")
+	}
+
+	var signed struct {
+		A int   `littlebyte:"int24"`
+		B int64 `littlebyte:"int16"`
+		C int   `littlebyte:"int64"`
+	}
+	s = NewString([]byte{0xfe, 0xff, 0xff, 0x02, 0x80, 5, 0, 0, 0, 0, 0, 0, 0})
+	if !s.ReadStruct(&signed) {
+		t.Fatal("ReadStruct() = false for tagged signed fields, want true")
+	}
+	if signed.A != -2 || signed.B != -0x7ffe || signed.C != 5 {
+		t.Errorf("ReadStruct() = %+v, want {A:-2 B:-32766 C:5}", signed)
+	}
+
+	var mismatch struct {
+		N int `littlebyte:"uint16"`
+	}
+	s = NewString(make([]byte, 2))
+	if s.ReadStruct(&mismatch) {
+		t.Error("ReadStruct() = true for signed field with unsigned tag, want false")
+	}
+}
+
+func TestReadStructPanic(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("recover() = nil, want error; ReadStruct() did not panic")
+		}
+	}()
+	s := NewString(nil)
+	var v testStruct
+	s.ReadStruct(v)
+}
+
+func TestAddStruct(t *testing.T) {
+	var b Builder
+	v := testStructValue
+	v.Skipped = 1
+	v.private = 2
+	b.AddStruct(v)
+	if err := builderBytesEq(&b, testStructBytes...); err != nil {
+		t.Error(err)
+	}
+
+	b = Builder{}
+	b.AddStruct(&v)
+	if err := builderBytesEq(&b, testStructBytes...); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestAddStructSigned(t *testing.T) {
+	type signed struct {
+		A int   `littlebyte:"int24"`
+		B int64 `littlebyte:"int16"`
+		C int   `littlebyte:"int64"`
+		D int8
+	}
+	want := signed{A: -1 << 23, B: 0x7fff, C: -5, D: -1}
+	var b Builder
+	b.AddStruct(want)
+	s := NewString(b.BytesOrPanic())
+	var got signed
+	if !s.ReadStruct(&got) || !s.Empty() {
+		t.Fatal("ReadStruct() failed on AddStruct output")
+	}
+	if got != want {
+		t.Errorf("ReadStruct() = %+v, want %+v", got, want)
+	}
+}
+
+func TestAddStructErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{}
+	}{
+		{"not a struct", 1},
+		{"overflow", struct {
+			N uint32 `littlebyte:"uint24"`
+		}{1 << 24}},
+		{"missing width", struct{ N int }{}},
+		{"signed overflow", struct {
+			N int `littlebyte:"int16"`
+		}{1 << 15}},
+		{"signed negative overflow", struct {
+			N int32 `littlebyte:"int8"`
+		}{-129}},
+		{"unsigned tag on signed field", struct {
+			N int `littlebyte:"uint16"`
+		}{}},
+		{"missing prefix", struct{ S string }{}},
+		{"unsupported", struct{ F float64 }{}},
+		{"prefix overflow", struct {
+			S string `littlebyte:"len8"`
+		}{string(make([]byte, 256))}},
+	}
+	for _, tt := range tests {
+		var b Builder
+		b.AddStruct(tt.v)
+		if _, err := b.Bytes(); err == nil {
+			t.Errorf("%s: Bytes() error = nil, want error", tt.name)
+		}
+	}
+}
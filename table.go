@@ -0,0 +1,34 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package littlebyte
+
+import "bytes"
+
+// A StringTable provides random access to the NUL-terminated strings in a
+// string table, such as those found in ELF and PE files. Strings in the table
+// are referred to by their byte offset from the start of the table.
+type StringTable struct {
+	data []byte
+}
+
+// NewStringTable creates a StringTable from the contents of s. The table
+// refers to the bytes of s; it does not copy them.
+func NewStringTable(s String) *StringTable {
+	return &StringTable{data: s.data}
+}
+
+// Lookup returns the NUL-terminated string that starts at the given offset in
+// the table. It reports whether the offset was within the table and the string
+// was terminated.
+func (t *StringTable) Lookup(offset int) (string, bool) {
+	if offset < 0 || offset >= len(t.data) {
+		return "", false
+	}
+	i := bytes.IndexByte(t.data[offset:], 0)
+	if i < 0 {
+		return "", false
+	}
+	return string(t.data[offset : offset+i]), true
+}
@@ -0,0 +1,37 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package littlebyte
+
+import "testing"
+
+func TestStringTable(t *testing.T) {
+	var b Builder
+	b.AddUint8(0)
+	b.AddBytes([]byte(".text\x00"))
+	b.AddBytes([]byte(".data\x00"))
+	b.AddBytes([]byte(".bss\x00"))
+	b.AddBytes([]byte("unterminated"))
+	table := NewStringTable(NewString(b.BytesOrPanic()))
+
+	for _, test := range []struct {
+		offset int
+		want   string
+		ok     bool
+	}{
+		{0, "", true},
+		{1, ".text", true},
+		{7, ".data", true},
+		{9, "ata", true},
+		{13, ".bss", true},
+		{18, "", false}, // unterminated
+		{30, "", false},
+		{-1, "", false},
+	} {
+		got, ok := table.Lookup(test.offset)
+		if got != test.want || ok != test.ok {
+			t.Errorf("Lookup(%d) = %q, %v; want %q, %v", test.offset, got, ok, test.want, test.ok)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package littlebyte
+
+import "hash"
+
+// TeeHash sets h to receive the bytes written to b from now on, so that a
+// checksum or digest of the output is available along with it. Passing nil
+// stops writing to the previous hash. Bytes already written to b are not
+// written to h.
+//
+// Bytes are written to h lazily, when they are committed, rather than as they
+// are appended. This keeps the bytes modifiable by Unwrite, Rollback, InsertAt
+// and Reserve patches until then, but means that for a Builder that is not
+// streaming, h reads the output in one pass when it is committed. Bytes are
+// committed when Bytes, BytesOrPanic or Sections is called, or when they are
+// written out by Flush. Each byte is committed only once, so calling these
+// methods repeatedly, or together, does not write it to h again. Length
+// prefixes are committed together with the values they precede, once the
+// outermost pending value is complete, so h always receives the bytes in the
+// same order as they appear in the output, prefixes included. Committed bytes
+// cannot be changed afterwards, so Unwrite, Rollback, InsertAt and Reserve
+// patches that would modify them panic.
+//
+// TeeHash should be called on a top-level Builder, not on a child passed to a
+// BuilderContinuation. It panics if b has a pending child.
+func (b *Builder) TeeHash(h hash.Hash) {
+	if b.child != nil {
+		panic("littlebyte: TeeHash called while child is pending")
+	}
+	b.tee = h
+	b.teed = len(b.result)
+}
+
+// feedTee writes buf[b.teed:n] to the Builder's hash, if any, and marks those
+// bytes as committed. buf is the Builder's current buffer, which is more up to
+// date in its innermost pending child than in b.result.
+func (b *Builder) feedTee(buf []byte, n int) {
+	if b.tee == nil || n <= b.teed {
+		return
+	}
+	b.tee.Write(buf[b.teed:n])
+	b.teed = n
+}
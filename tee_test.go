@@ -0,0 +1,102 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package littlebyte
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"testing"
+)
+
+func TestTeeHash(t *testing.T) {
+	var b Builder
+	b.AddUint8(0xff) // not hashed
+	h := sha256.New()
+	b.TeeHash(h)
+	b.AddUint8(1)
+	b.AddUint16LengthPrefixed(func(c *Builder) {
+		c.AddUint8(2)
+		c.AddUint8LengthPrefixed(func(c *Builder) {
+			c.AddBytes([]byte("abc"))
+		})
+	})
+	b.BytesOrPanic()
+	b.AddUint8(3)
+	out := b.BytesOrPanic() // only the new byte is hashed
+
+	want := sha256.Sum256(out[1:])
+	if got := h.Sum(nil); !bytes.Equal(got, want[:]) {
+		t.Errorf("h.Sum() = %x, want %x", got, want)
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("recover() = nil, want error; Unwrite() did not panic")
+			}
+		}()
+		b.Unwrite(1)
+	}()
+}
+
+func TestTeeHashCommitOnce(t *testing.T) {
+	var b Builder
+	h := sha256.New()
+	b.TeeHash(h)
+	b.AddUint16LengthPrefixed(func(c *Builder) {
+		c.AddBytes([]byte("abc"))
+	})
+	out := b.BytesOrPanic()
+	want := h.Sum(nil)
+	if sum := sha256.Sum256(out); !bytes.Equal(want, sum[:]) {
+		t.Errorf("h.Sum() = %x, want %x", want, sum)
+	}
+
+	b.BytesOrPanic()
+	b.SetTrackSections(true)
+	if _, err := b.Sections(); err != nil {
+		t.Fatal(err)
+	}
+	if got := h.Sum(nil); !bytes.Equal(got, want) {
+		t.Errorf("h.Sum() = %x after Bytes and Sections, want %x", got, want)
+	}
+}
+
+func TestTeeHashStreaming(t *testing.T) {
+	var buf bytes.Buffer
+	b := NewStreamingBuilder(&buf)
+	h := sha256.New()
+	b.TeeHash(h)
+	b.AddUint8(1)
+	b.AddUint16LengthPrefixed(func(c *Builder) {
+		c.AddUint8(2)
+		if err := b.Flush(); err != nil {
+			t.Fatal(err)
+		}
+		c.AddUint32LengthPrefixed(func(c *Builder) {
+			c.AddUint8(3)
+		})
+	})
+	patch := b.Reserve(1)
+	if err := b.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	b.AddUint8(4)
+	if err := b.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := sha256.Sum256(buf.Bytes())
+	if got := h.Sum(nil); !bytes.Equal(got, want[:]) {
+		t.Errorf("h.Sum() = %x, want %x", got, want)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("recover() = nil, want error; patch() did not panic")
+		}
+	}()
+	patch([]byte{5})
+}
@@ -0,0 +1,50 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package littlebyte
+
+import "time"
+
+// AddUnixSeconds32 appends t as a little-endian, 32-bit count of seconds since
+// the Unix epoch. Fractional seconds are discarded. Only times from 1970 until
+// early 2106 can be represented; other times are truncated to 32 bits, like
+// values passed to AddUint24.
+func (b *Builder) AddUnixSeconds32(t time.Time) {
+	b.AddUint32(uint32(t.Unix()))
+}
+
+// ReadUnixSeconds32 decodes a little-endian, 32-bit count of seconds since the
+// Unix epoch into out and advances over it. It reports whether the read was
+// successful. The time is in the local time zone, as for time.Unix.
+func (s *String) ReadUnixSeconds32(out *time.Time) bool {
+	var v uint32
+	if !s.readUnsigned(&v, 4) {
+		return s.fail("ReadUnixSeconds32")
+	}
+	*out = time.Unix(int64(v), 0)
+	return true
+}
+
+// AddUnixNanos64 appends t as a little-endian, 64-bit count of nanoseconds
+// since the Unix epoch. The result is undefined for times that t.UnixNano
+// cannot represent, which are those before 1678 or after 2262.
+func (b *Builder) AddUnixNanos64(t time.Time) {
+	v := uint64(t.UnixNano())
+	b.AddUint32(uint32(v))
+	b.AddUint32(uint32(v >> 32))
+}
+
+// ReadUnixNanos64 decodes a little-endian, 64-bit count of nanoseconds since
+// the Unix epoch into out and advances over it. The count is treated as
+// signed, so that it round-trips with AddUnixNanos64. It reports whether the
+// read was successful. The time is in the local time zone, as for time.Unix.
+func (s *String) ReadUnixNanos64(out *time.Time) bool {
+	var lo, hi uint32
+	v := NewString(s.read(8))
+	if v.Empty() || !v.readUnsigned(&lo, 4) || !v.readUnsigned(&hi, 4) {
+		return s.fail("ReadUnixNanos64")
+	}
+	*out = time.Unix(0, int64(uint64(hi)<<32|uint64(lo)))
+	return true
+}
@@ -0,0 +1,73 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package littlebyte
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUnixSeconds32(t *testing.T) {
+	tm := time.Date(2017, 6, 1, 12, 0, 0, 999, time.UTC)
+	var b Builder
+	b.AddUnixSeconds32(tm)
+	// 1496318400 = 0x593001c0
+	if err := builderBytesEq(&b, 0xc0, 0x01, 0x30, 0x59); err != nil {
+		t.Error(err)
+	}
+
+	s := NewString(b.BytesOrPanic())
+	var got time.Time
+	if !s.ReadUnixSeconds32(&got) {
+		t.Fatal("ReadUnixSeconds32() = false, want true")
+	}
+	if want := tm.Truncate(time.Second); !got.Equal(want) {
+		t.Errorf("ReadUnixSeconds32() = %v, want %v", got, want)
+	}
+	if s.ReadUnixSeconds32(&got) {
+		t.Error("ReadUnixSeconds32() = true on empty input, want false")
+	}
+
+	// Times after early 2106 wrap around.
+	b = Builder{}
+	b.AddUnixSeconds32(time.Unix(1<<32+5, 0))
+	if err := builderBytesEq(&b, 5, 0, 0, 0); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestUnixNanos64(t *testing.T) {
+	for _, tm := range []time.Time{
+		time.Date(2017, 6, 1, 12, 0, 0, 123456789, time.UTC),
+		time.Date(1960, 1, 1, 0, 0, 0, 1, time.UTC),
+		time.Unix(0, 0),
+	} {
+		var b Builder
+		b.AddUnixNanos64(tm)
+		s := NewString(b.BytesOrPanic())
+		if s.Len() != 8 {
+			t.Errorf("AddUnixNanos64(%v) wrote %d bytes, want 8", tm, s.Len())
+		}
+		var got time.Time
+		if !s.ReadUnixNanos64(&got) {
+			t.Fatal("ReadUnixNanos64() = false, want true")
+		}
+		if !got.Equal(tm) {
+			t.Errorf("ReadUnixNanos64() = %v, want %v", got, tm)
+		}
+	}
+
+	var b Builder
+	b.AddUnixNanos64(time.Unix(0, 0x0102030405060708))
+	if err := builderBytesEq(&b, 8, 7, 6, 5, 4, 3, 2, 1); err != nil {
+		t.Error(err)
+	}
+
+	s := NewString([]byte{1, 2, 3, 4, 5, 6, 7})
+	var got time.Time
+	if s.ReadUnixNanos64(&got) {
+		t.Error("ReadUnixNanos64() = true on short input, want false")
+	}
+}
@@ -0,0 +1,36 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package littlebyte
+
+// AddTLV adds a tag-length-value record: the low tagWidth bytes of tag, then
+// the length of the value as a little-endian prefix of lenWidth bytes, then
+// the value written by f. It panics if tagWidth or lenWidth is not between 1
+// and 8.
+func (b *Builder) AddTLV(tagWidth, lenWidth int, tag uint64, f BuilderContinuation) {
+	if lenWidth < 1 || lenWidth > 8 {
+		panic("littlebyte: invalid integer width")
+	}
+	b.AddUint(tag, tagWidth)
+	b.addLengthPrefixed(lenWidth, false, f)
+}
+
+// ReadTLV reads a tag-length-value record, as written by AddTLV, setting
+// outTag to its tag and outValue to its value, and advances over it. It
+// reports whether the read was successful; if not, s is unchanged. It panics
+// if tagWidth or lenWidth is not between 1 and 8.
+func (s *String) ReadTLV(tagWidth, lenWidth int, outTag *uint64, outValue *String) bool {
+	if lenWidth < 1 || lenWidth > 8 {
+		panic("littlebyte: invalid integer width")
+	}
+	t := *s
+	var tag, length uint64
+	if !t.ReadUint(&tag, tagWidth) || !t.ReadUint(&length, lenWidth) || length > uint64(len(t.data)) {
+		return s.fail("ReadTLV")
+	}
+	*outTag = tag
+	*outValue = NewString(t.read(int(length)))
+	*s = t
+	return true
+}
@@ -0,0 +1,53 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package littlebyte
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestTLV(t *testing.T) {
+	var b Builder
+	b.AddTLV(1, 2, 7, func(c *Builder) {
+		c.AddBytes([]byte("abc"))
+	})
+	b.AddTLV(3, 1, 0x010203, func(c *Builder) {})
+	if err := builderBytesEq(&b, 7, 3, 0, 'a', 'b', 'c', 3, 2, 1, 0); err != nil {
+		t.Error(err)
+	}
+
+	s := NewString(b.BytesOrPanic())
+	var tag uint64
+	var v String
+	if !s.ReadTLV(1, 2, &tag, &v) {
+		t.Fatal("ReadTLV() = false, want true")
+	}
+	if tag != 7 || !bytes.Equal(v.Bytes(), []byte("abc")) {
+		t.Errorf("ReadTLV() = %d, %q; want 7, \"abc\"", tag, v.Bytes())
+	}
+	if !s.ReadTLV(3, 1, &tag, &v) || !s.Empty() {
+		t.Fatal("ReadTLV() = false, want true")
+	}
+	if tag != 0x010203 || !v.Empty() {
+		t.Errorf("ReadTLV() = %#x, %q; want 0x10203, \"\"", tag, v.Bytes())
+	}
+
+	b = Builder{}
+	b.AddTLV(1, 1, 0, func(c *Builder) {
+		c.AddZeros(256)
+	})
+	if _, err := b.Bytes(); err == nil {
+		t.Error("Bytes() error = nil for an overflowing length, want error")
+	}
+
+	s = NewString([]byte{7, 4, 0, 1, 2, 3})
+	if s.ReadTLV(1, 2, &tag, &v) {
+		t.Error("ReadTLV() = true for truncated value, want false")
+	}
+	if s.Len() != 6 {
+		t.Errorf("s.Len() = %d, want 6", s.Len())
+	}
+}
@@ -0,0 +1,46 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package littlebyte
+
+// AddUnion8 adds a member of a tagged union: an 8-bit tag followed by a
+// little-endian, 16-bit length-prefixed body written by f.
+func (b *Builder) AddUnion8(tag uint8, f BuilderContinuation) {
+	b.AddUint8(tag)
+	b.AddUint16LengthPrefixed(f)
+}
+
+// ReadUnion8 reads a member of a tagged union, as written by AddUnion8, and
+// advances over it. It calls the handler for the member's tag with the body of
+// the member. It reports whether the read was successful, which requires that
+// there is a handler for the tag and that the handler returns true.
+func (s *String) ReadUnion8(handlers map[uint8]func(body *String) bool) bool {
+	var tag uint32
+	var body String
+	if !s.readUnsigned(&tag, 1) || !s.readLengthPrefixed(2, &body) {
+		return s.fail("ReadUnion8")
+	}
+	h, ok := handlers[uint8(tag)]
+	if !ok || !h(&body) {
+		return s.fail("ReadUnion8")
+	}
+	return true
+}
+
+// ReadUint8Dispatch reads an 8-bit tag and calls the handler for it with s,
+// which has been advanced past the tag, so that the handler reads the rest of
+// the value directly. Unlike ReadUnion8, the body is not length-prefixed. It
+// reports whether the read was successful, which requires that there is a
+// handler for the tag and that the handler returns true.
+func (s *String) ReadUint8Dispatch(handlers map[uint8]func(*String) bool) bool {
+	var tag uint32
+	if !s.readUnsigned(&tag, 1) {
+		return s.fail("ReadUint8Dispatch")
+	}
+	h, ok := handlers[uint8(tag)]
+	if !ok || !h(s) {
+		return s.fail("ReadUint8Dispatch")
+	}
+	return true
+}
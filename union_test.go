@@ -0,0 +1,83 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package littlebyte
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestUnion8(t *testing.T) {
+	var b Builder
+	b.AddUnion8(3, func(c *Builder) {
+		c.AddBytes([]byte("hello"))
+	})
+	if err := builderBytesEq(&b, 3, 5, 0, 'h', 'e', 'l', 'l', 'o'); err != nil {
+		t.Error(err)
+	}
+
+	var got []byte
+	var gotTag uint8
+	handlers := map[uint8]func(*String) bool{
+		1: func(body *String) bool {
+			gotTag = 1
+			return true
+		},
+		3: func(body *String) bool {
+			gotTag = 3
+			return body.ReadBytes(&got, 5)
+		},
+	}
+	s := NewString(b.BytesOrPanic())
+	if !s.ReadUnion8(handlers) {
+		t.Fatal("ReadUnion8() = false, want true")
+	}
+	if gotTag != 3 || !bytes.Equal(got, []byte("hello")) {
+		t.Errorf("tag, body = %d, %q; want 3, %q", gotTag, got, "hello")
+	}
+	if s.Len() != 0 {
+		t.Errorf("s.Len() = %d, want 0", s.Len())
+	}
+
+	s = NewString([]byte{2, 0, 0})
+	if s.ReadUnion8(handlers) {
+		t.Error("ReadUnion8() = true for unknown tag, want false")
+	}
+	s = NewString([]byte{3, 1, 0, 'h'})
+	if s.ReadUnion8(handlers) {
+		t.Error("ReadUnion8() = true for failing handler, want false")
+	}
+}
+
+func TestReadUint8Dispatch(t *testing.T) {
+	var u16 uint16
+	var u32 uint32
+	handlers := map[uint8]func(*String) bool{
+		1: func(s *String) bool {
+			return s.ReadUint16(&u16)
+		},
+		2: func(s *String) bool {
+			return s.ReadUint32(&u32)
+		},
+	}
+	s := NewString([]byte{1, 0x34, 0x12, 2, 0x78, 0x56, 0x34, 0x12, 9})
+	if !s.ReadUint8Dispatch(handlers) || !s.ReadUint8Dispatch(handlers) {
+		t.Fatal("ReadUint8Dispatch() = false, want true")
+	}
+	if u16 != 0x1234 || u32 != 0x12345678 {
+		t.Errorf("u16, u32 = %#x, %#x; want 0x1234, 0x12345678", u16, u32)
+	}
+	if s.ReadUint8Dispatch(handlers) {
+		t.Error("ReadUint8Dispatch() = true for unknown tag, want false")
+	}
+
+	s = NewString([]byte{2, 1})
+	if s.ReadUint8Dispatch(handlers) {
+		t.Error("ReadUint8Dispatch() = true for failing handler, want false")
+	}
+	if s = NewString(nil); s.ReadUint8Dispatch(handlers) {
+		t.Error("ReadUint8Dispatch() = true for empty input, want false")
+	}
+}
@@ -0,0 +1,267 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package littlebyte
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// AddUvarint appends v as an unsigned varint, in the format of
+// encoding/binary and Protocol Buffers.
+func (b *Builder) AddUvarint(v uint64) {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(buf[:], v)
+	b.add(buf[:n]...)
+}
+
+// AddUvarintLengthPrefixed adds a byte sequence prefixed with its length as an
+// unsigned varint.
+//
+// The width of the prefix is not known until the content has been written, so
+// a single byte is reserved for it. If the content is 128 bytes or longer, the
+// content is shifted to make room for the longer prefix once the continuation
+// returns, which costs a copy of the content. Nested uvarint length-prefixed
+// values may therefore be copied once for each level of nesting.
+func (b *Builder) AddUvarintLengthPrefixed(f BuilderContinuation) {
+	b.addChild(&Builder{pendingLenLen: 1, pendingUvarint: true}, f)
+}
+
+// putVarPrefix writes the length of b's content into its reserved uvarint or
+// auto length prefix, shifting the content if the prefix needs more room. If
+// the length does not fit in the prefix, the error is a lengthCheckError.
+func (b *Builder) putVarPrefix(length int) error {
+	var buf [binary.MaxVarintLen64]byte
+	var n int
+	if b.pendingUvarint {
+		n = binary.PutUvarint(buf[:], uint64(length))
+	} else {
+		var ok bool
+		if n, ok = putAutoLength(buf[:], length); !ok {
+			return lengthCheckError{fmt.Errorf("littlebyte: pending child length %d exceeds auto length prefix at depth %d", length, b.depth)}
+		}
+	}
+	if extra := n - b.pendingLenLen; extra > 0 {
+		if b.fixedSize && len(b.result)+extra > cap(b.result) {
+			return errors.New("littlebyte: Builder is exceeding its fixed-size buffer")
+		}
+		start := b.offset + b.pendingLenLen
+		if len(b.result)+extra > cap(b.result) {
+			b.owned = true
+		}
+		b.result = append(b.result, buf[:extra]...)
+		copy(b.result[start+extra:], b.result[start:start+length])
+	}
+	copy(b.result[b.offset:], buf[:n])
+	return nil
+}
+
+// ReadUvarint decodes an unsigned varint, in the format of encoding/binary
+// and Protocol Buffers, into out and advances over it. It reports whether the
+// read was successful.
+func (s *String) ReadUvarint(out *uint64) bool {
+	return s.readUvarint(out) || s.fail("ReadUvarint")
+}
+
+func (s *String) readUvarint(out *uint64) bool {
+	v, n := binary.Uvarint(s.data)
+	if n <= 0 {
+		return false
+	}
+	s.read(n)
+	*out = v
+	return true
+}
+
+// ReadUvarintLengthPrefixed reads the content of a value prefixed with its
+// length as an unsigned varint into out and advances over it. It reports
+// whether the read was successful.
+func (s *String) ReadUvarintLengthPrefixed(out *String) bool {
+	var length uint64
+	if !s.readUvarint(&length) || length > uint64(len(s.data)) {
+		return s.fail("ReadUvarintLengthPrefixed")
+	}
+	*out = NewString(s.read(int(length)))
+	return true
+}
+
+// AddDelimited appends record prefixed with its length as an unsigned varint,
+// following the Protocol Buffers convention for streams of delimited messages.
+func (b *Builder) AddDelimited(record []byte) {
+	b.AddUvarint(uint64(len(record)))
+	b.AddBytes(record)
+}
+
+// ReadDelimited reads a record prefixed with its length as an unsigned
+// varint, as written by AddDelimited, and advances over it. It sets out to a
+// slice of the String's bytes. It reports whether the read was successful; if
+// not, s is unchanged.
+func (s *String) ReadDelimited(out *[]byte) bool {
+	var length uint64
+	t := *s
+	if !t.readUvarint(&length) || length > uint64(len(t.data)) {
+		return s.fail("ReadDelimited")
+	}
+	*out = t.read(int(length))
+	*s = t
+	return true
+}
+
+// AddAutoLengthPrefixed adds a byte sequence prefixed with its length in the
+// smallest of three little-endian prefix widths. The low two bits of the
+// first byte select the width, and the remaining bits hold the length:
+//
+//	xxxxxx00                             1 byte,  length < 1<<6
+//	xxxxxx01 xxxxxxxx                    2 bytes, length < 1<<14
+//	xxxxxx10 xxxxxxxx xxxxxxxx xxxxxxxx  4 bytes, length < 1<<30
+//
+// A first byte with both low bits set is invalid. This matches the compact
+// integer encoding of the SCALE codec for values below 1<<30. Content of 1<<30
+// bytes or more overflows the prefix, which is reported as for other length
+// prefixes, including in strict mode (see SetStrictLengthChecks). As with
+// AddUvarintLengthPrefixed, content of 64 bytes or more is shifted once the
+// continuation returns.
+func (b *Builder) AddAutoLengthPrefixed(f BuilderContinuation) {
+	b.addChild(&Builder{pendingLenLen: 1, pendingAutoLen: true}, f)
+}
+
+// putAutoLength encodes length as an auto length prefix into buf and returns
+// the number of bytes written. It reports false if length is too large.
+func putAutoLength(buf []byte, length int) (int, bool) {
+	var n int
+	var tag uint32
+	switch {
+	case length < 1<<6:
+		n, tag = 1, 0
+	case length < 1<<14:
+		n, tag = 2, 1
+	case length < 1<<30:
+		n, tag = 4, 2
+	default:
+		return 0, false
+	}
+	v := uint32(length)<<2 | tag
+	for i := 0; i < n; i++ {
+		buf[i] = uint8(v)
+		v >>= 8
+	}
+	return n, true
+}
+
+// ReadAutoLengthPrefixed reads the content of a value prefixed with its length
+// as written by AddAutoLengthPrefixed into out and advances over it. A prefix
+// wider than needed for its length is rejected, so that each value has exactly
+// one encoding. It reports whether the read was successful; if not, s is
+// unchanged.
+func (s *String) ReadAutoLengthPrefixed(out *String) bool {
+	t := *s
+	if len(t.data) == 0 {
+		return s.fail("ReadAutoLengthPrefixed")
+	}
+	var width int
+	var min uint32
+	switch t.data[0] & 3 {
+	case 0:
+		width, min = 1, 0
+	case 1:
+		width, min = 2, 1<<6
+	case 2:
+		width, min = 4, 1<<14
+	default:
+		return s.fail("ReadAutoLengthPrefixed")
+	}
+	var v uint32
+	if !t.readUnsigned(&v, width) {
+		return s.fail("ReadAutoLengthPrefixed")
+	}
+	length := v >> 2
+	if length < min || uint64(length) > uint64(len(t.data)) {
+		return s.fail("ReadAutoLengthPrefixed")
+	}
+	*out = NewString(t.read(int(length)))
+	*s = t
+	return true
+}
+
+// AddGroupVarint appends four values in group varint encoding: a tag byte
+// followed by each value as a little-endian integer of 1 to 4 bytes. Bits
+// 2i and 2i+1 of the tag hold one less than the width of vi.
+func (b *Builder) AddGroupVarint(v0, v1, v2, v3 uint32) {
+	vs := [4]uint32{v0, v1, v2, v3}
+	var tag uint8
+	var widths [4]int
+	n := 1
+	for i, v := range vs {
+		w := 1
+		for v >= 1<<8 {
+			v >>= 8
+			w++
+		}
+		widths[i] = w
+		tag |= uint8(w-1) << (2 * uint(i))
+		n += w
+	}
+	buf := b.extend(n)
+	if buf == nil {
+		return
+	}
+	buf[0] = tag
+	buf = buf[1:]
+	for i, v := range vs {
+		for j := 0; j < widths[i]; j++ {
+			buf[j] = uint8(v)
+			v >>= 8
+		}
+		buf = buf[widths[i]:]
+	}
+}
+
+// ReadGroupVarint decodes four values in group varint encoding, as written
+// by AddGroupVarint, into out and advances over them. It reports whether the
+// read was successful; if not, s is unchanged.
+func (s *String) ReadGroupVarint(out *[4]uint32) bool {
+	t := *s
+	var tag uint8
+	if !t.ReadUint8(&tag) {
+		return s.fail("ReadGroupVarint")
+	}
+	var vs [4]uint32
+	for i := range vs {
+		w := int(tag>>(2*uint(i))&3) + 1
+		if !t.readUnsigned(&vs[i], w) {
+			return s.fail("ReadGroupVarint")
+		}
+	}
+	*out = vs
+	*s = t
+	return true
+}
+
+// AddPrefixedWidthUint appends v as a byte giving a width between 1 and 8,
+// followed by v as a little-endian integer of that many bytes. The smallest
+// width that holds v is used.
+func (b *Builder) AddPrefixedWidthUint(v uint64) {
+	width := 1
+	for x := v >> 8; x != 0; x >>= 8 {
+		width++
+	}
+	b.AddUint8(uint8(width))
+	b.AddUint(v, width)
+}
+
+// ReadPrefixedWidthUint reads an integer preceded by a byte giving its width,
+// as written by AddPrefixedWidthUint, into out and advances over it. The width
+// must be between 1 and 8, but need not be the smallest possible. It reports
+// whether the read was successful; if not, s is unchanged.
+func (s *String) ReadPrefixedWidthUint(out *uint64) bool {
+	t := *s
+	var width uint8
+	if !t.ReadUint8(&width) || width < 1 || width > 8 || !t.ReadUint(out, int(width)) {
+		return s.fail("ReadPrefixedWidthUint")
+	}
+	*s = t
+	return true
+}
@@ -0,0 +1,238 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package littlebyte
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestUvarint(t *testing.T) {
+	var b Builder
+	b.AddUvarint(0)
+	b.AddUvarint(300)
+	b.AddUvarint(1<<64 - 1)
+	if err := builderBytesEq(&b, 0, 0xac, 0x02, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01); err != nil {
+		t.Error(err)
+	}
+
+	s := NewString(b.BytesOrPanic())
+	var x, y, z uint64
+	if !s.ReadUvarint(&x) || !s.ReadUvarint(&y) || !s.ReadUvarint(&z) {
+		t.Fatal("parsing failed")
+	}
+	if x != 0 || y != 300 || z != 1<<64-1 {
+		t.Errorf("x, y, z = %d, %d, %d; want 0, 300, %d", x, y, z, uint64(1<<64-1))
+	}
+	if s.Len() != 0 {
+		t.Errorf("s.Len() = %d, want 0", s.Len())
+	}
+
+	s = NewString([]byte{0x80, 0x80})
+	if s.ReadUvarint(&x) {
+		t.Error("ReadUvarint() = true on truncated input, want false")
+	}
+}
+
+func TestUvarintLengthPrefixed(t *testing.T) {
+	for _, n := range []int{0, 1, 127, 128, 300, 16384} {
+		payload := bytes.Repeat([]byte{0xab}, n)
+		var b Builder
+		b.AddUint8(1)
+		b.AddUvarintLengthPrefixed(func(c *Builder) {
+			c.AddUvarintLengthPrefixed(func(d *Builder) {
+				d.AddBytes(payload)
+			})
+		})
+		b.AddUint8(2)
+
+		s := NewString(b.BytesOrPanic())
+		var x, y uint8
+		var outer, inner String
+		if !s.ReadUint8(&x) || !s.ReadUvarintLengthPrefixed(&outer) || !s.ReadUint8(&y) || !s.Empty() {
+			t.Errorf("n=%d: parsing failed", n)
+			continue
+		}
+		if !outer.ReadUvarintLengthPrefixed(&inner) || !outer.Empty() {
+			t.Errorf("n=%d: parsing outer failed", n)
+			continue
+		}
+		if x != 1 || y != 2 || !bytes.Equal(inner.Bytes(), payload) {
+			t.Errorf("n=%d: x, y, inner.Len() = %d, %d, %d; want 1, 2, %d", n, x, y, inner.Len(), n)
+		}
+	}
+
+	b := NewFixedBuilder(make([]byte, 0, 129))
+	b.AddUvarintLengthPrefixed(func(c *Builder) {
+		c.AddZeros(128)
+	})
+	if _, err := b.Bytes(); err == nil {
+		t.Error("Bytes() error = nil, want error for exceeding fixed-size buffer")
+	}
+
+	s := NewString([]byte{3, 1, 2})
+	var out String
+	if s.ReadUvarintLengthPrefixed(&out) {
+		t.Error("ReadUvarintLengthPrefixed() = true on truncated input, want false")
+	}
+}
+
+func TestDelimited(t *testing.T) {
+	records := [][]byte{[]byte("first"), {}, bytes.Repeat([]byte{'x'}, 200)}
+	var b Builder
+	for _, r := range records {
+		b.AddDelimited(r)
+	}
+	s := NewString(b.BytesOrPanic())
+	if got, want := s.Len(), 1+5+1+2+200; got != want {
+		t.Errorf("Len() = %d, want %d", got, want)
+	}
+	for i, want := range records {
+		var got []byte
+		if !s.ReadDelimited(&got) {
+			t.Fatalf("ReadDelimited() #%d = false, want true", i)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("ReadDelimited() #%d = %q, want %q", i, got, want)
+		}
+	}
+	if !s.Empty() {
+		t.Errorf("s.Len() = %d, want 0", s.Len())
+	}
+
+	s = NewString([]byte{3, 'a', 'b'})
+	var got []byte
+	if s.ReadDelimited(&got) {
+		t.Error("ReadDelimited() = true on truncated record, want false")
+	}
+	if s.Len() != 3 {
+		t.Errorf("ReadDelimited() advanced on failure")
+	}
+}
+
+func TestAutoLengthPrefixed(t *testing.T) {
+	for _, test := range []struct {
+		n      int
+		prefix []byte
+	}{
+		{0, []byte{0x00}},
+		{63, []byte{0xfc}},
+		{64, []byte{0x01, 0x01}},
+		{1<<14 - 1, []byte{0xfd, 0xff}},
+		{1 << 14, []byte{0x02, 0x00, 0x01, 0x00}},
+	} {
+		payload := bytes.Repeat([]byte{0xab}, test.n)
+		var b Builder
+		b.AddUint8(1)
+		b.AddAutoLengthPrefixed(func(c *Builder) {
+			c.AddBytes(payload)
+		})
+		b.AddUint8(2)
+		out := b.BytesOrPanic()
+		if got := out[1 : 1+len(test.prefix)]; !bytes.Equal(got, test.prefix) {
+			t.Errorf("n=%d: prefix = %x, want %x", test.n, got, test.prefix)
+		}
+
+		s := NewString(out)
+		var x, y uint8
+		var v String
+		if !s.ReadUint8(&x) || !s.ReadAutoLengthPrefixed(&v) || !s.ReadUint8(&y) || !s.Empty() {
+			t.Errorf("n=%d: parsing failed", test.n)
+			continue
+		}
+		if !bytes.Equal(v.Bytes(), payload) || x != 1 || y != 2 {
+			t.Errorf("n=%d: round trip mismatch", test.n)
+		}
+	}
+
+	for _, in := range [][]byte{
+		{},
+		{0x03},             // invalid width
+		{0x04},             // truncated content
+		{0x01},             // truncated prefix
+		{0x01, 0x00, 0xab}, // non-minimal prefix
+	} {
+		s := NewString(in)
+		var v String
+		if s.ReadAutoLengthPrefixed(&v) {
+			t.Errorf("ReadAutoLengthPrefixed(%x) = true, want false", in)
+		}
+		if s.Len() != len(in) {
+			t.Errorf("ReadAutoLengthPrefixed(%x) advanced on failure", in)
+		}
+	}
+}
+
+func TestGroupVarint(t *testing.T) {
+	var b Builder
+	b.AddGroupVarint(1, 0x100, 0x10000, 0x1000000)
+	b.AddGroupVarint(0xffffffff, 0, 0xffff, 0xff)
+	if err := builderBytesEq(&b,
+		0xe4, 1, 0, 1, 0, 0, 1, 0, 0, 0, 1,
+		0x13, 0xff, 0xff, 0xff, 0xff, 0, 0xff, 0xff, 0xff,
+	); err != nil {
+		t.Error(err)
+	}
+
+	s := NewString(b.BytesOrPanic())
+	var x, y [4]uint32
+	if !s.ReadGroupVarint(&x) || !s.ReadGroupVarint(&y) || !s.Empty() {
+		t.Fatal("parsing failed")
+	}
+	if want := [4]uint32{1, 0x100, 0x10000, 0x1000000}; x != want {
+		t.Errorf("x = %#x, want %#x", x, want)
+	}
+	if want := [4]uint32{0xffffffff, 0, 0xffff, 0xff}; y != want {
+		t.Errorf("y = %#x, want %#x", y, want)
+	}
+
+	s = NewString([]byte{0xe4, 1, 0, 1})
+	if s.ReadGroupVarint(&x) {
+		t.Error("ReadGroupVarint() = true on truncated input, want false")
+	}
+	if s.Len() != 4 {
+		t.Errorf("s.Len() = %d, want 4", s.Len())
+	}
+}
+
+func TestPrefixedWidthUint(t *testing.T) {
+	var b Builder
+	b.AddPrefixedWidthUint(0)
+	b.AddPrefixedWidthUint(0x100)
+	b.AddPrefixedWidthUint(1<<64 - 1)
+	if err := builderBytesEq(&b, 1, 0, 2, 0, 1, 8, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff); err != nil {
+		t.Error(err)
+	}
+
+	s := NewString(b.BytesOrPanic())
+	var x, y, z uint64
+	if !s.ReadPrefixedWidthUint(&x) || !s.ReadPrefixedWidthUint(&y) || !s.ReadPrefixedWidthUint(&z) || !s.Empty() {
+		t.Fatal("parsing failed")
+	}
+	if x != 0 || y != 0x100 || z != 1<<64-1 {
+		t.Errorf("x, y, z = %d, %d, %d; want 0, 256, %d", x, y, z, uint64(1<<64-1))
+	}
+
+	// Wider than necessary is accepted.
+	s = NewString([]byte{3, 1, 0, 0})
+	if !s.ReadPrefixedWidthUint(&x) || x != 1 {
+		t.Errorf("ReadPrefixedWidthUint() read %d, want 1", x)
+	}
+
+	for _, in := range [][]byte{
+		{},
+		{0},
+		{9, 0, 0, 0, 0, 0, 0, 0, 0, 0},
+		{2, 0},
+	} {
+		s := NewString(in)
+		if s.ReadPrefixedWidthUint(&x) {
+			t.Errorf("ReadPrefixedWidthUint(%x) = true, want false", in)
+		}
+		if s.Len() != len(in) {
+			t.Errorf("ReadPrefixedWidthUint(%x) advanced on failure", in)
+		}
+	}
+}